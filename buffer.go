@@ -0,0 +1,110 @@
+package discordwebhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// maxPooledBufferSize évite de conserver dans le pool les buffers agrandis
+// par de gros fichiers joints
+const maxPooledBufferSize = 1 << 20
+
+// requestBuffer associe un buffer à un encodeur JSON réutilisable
+type requestBuffer struct {
+	bytes.Buffer
+	enc *json.Encoder
+}
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		b := &requestBuffer{}
+		b.enc = json.NewEncoder(&b.Buffer)
+		b.enc.SetEscapeHTML(false)
+		return b
+	},
+}
+
+// getBuffer retourne un buffer vide issu du pool
+func getBuffer() *requestBuffer {
+	b := bufferPool.Get().(*requestBuffer)
+	b.Reset()
+	return b
+}
+
+// release rend le buffer au pool, il ne doit plus être utilisé ensuite
+func (b *requestBuffer) release() {
+	if b.Cap() > maxPooledBufferSize {
+		return
+	}
+	bufferPool.Put(b)
+}
+
+// encode ajoute v au buffer au format JSON, sans saut de ligne final
+func (b *requestBuffer) encode(v any) error {
+	if err := b.enc.Encode(v); err != nil {
+		return err
+	}
+	b.Truncate(b.Len() - 1)
+	return nil
+}
+
+// lockedReader lit un buffer jusqu'à sa fermeture. Le transport HTTP pouvant
+// encore lire le corps d'une requête après avoir retourné la réponse, le
+// fermer garantit qu'un buffer rendu au pool ne sera plus lu
+type lockedReader struct {
+	mu     sync.Mutex
+	r      *bytes.Reader
+	closed bool
+}
+
+func newLockedReader(b []byte) *lockedReader {
+	return &lockedReader{r: bytes.NewReader(b)}
+}
+
+func (l *lockedReader) Read(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return 0, io.ErrClosedPipe
+	}
+	return l.r.Read(p)
+}
+
+func (l *lockedReader) Close() error {
+	l.mu.Lock()
+	l.closed = true
+	l.mu.Unlock()
+	return nil
+}
+
+// bodyReaders crée les lecteurs du corps d'une requête, y compris ceux
+// demandés par le transport via Request.GetBody pour renvoyer la requête, et
+// les ferme tous avant que le buffer ne soit rendu au pool
+type bodyReaders struct {
+	mu      sync.Mutex
+	b       []byte
+	readers []*lockedReader
+	closed  bool
+}
+
+func (r *bodyReaders) open() (io.ReadCloser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil, io.ErrClosedPipe
+	}
+	reader := newLockedReader(r.b)
+	r.readers = append(r.readers, reader)
+	return reader, nil
+}
+
+func (r *bodyReaders) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	for _, reader := range r.readers {
+		reader.Close()
+	}
+}
@@ -0,0 +1,306 @@
+package discordwebhook
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// EmbedBuilder construit un DiscordEmbed en validant les limites de Discord au
+// fur et à mesure des appels
+type EmbedBuilder struct {
+	embed DiscordEmbed
+	v     validator
+}
+
+// NewEmbed crée un nouveau constructeur d'embed
+func NewEmbed() *EmbedBuilder {
+	return &EmbedBuilder{}
+}
+
+// Title définit le titre de l'embed
+func (b *EmbedBuilder) Title(title string) *EmbedBuilder {
+	b.embed.Title = title
+	b.v.reset("title")
+	b.v.maxLength("title", title, MaxEmbedTitleLength)
+	return b
+}
+
+// URL définit le lien du titre de l'embed
+func (b *EmbedBuilder) URL(url string) *EmbedBuilder {
+	b.embed.Url = url
+	return b
+}
+
+// Description définit la description de l'embed
+func (b *EmbedBuilder) Description(description string) *EmbedBuilder {
+	b.embed.Description = description
+	b.v.reset("description")
+	b.v.maxLength("description", description, MaxEmbedDescriptionLength)
+	return b
+}
+
+// Color définit la couleur de l'embed, ex: 0x3498db
+func (b *EmbedBuilder) Color(color int) *EmbedBuilder {
+	b.embed.Color = color
+	b.v.reset("color")
+	if color < 0 || color > 0xffffff {
+		b.v.addf("color", "color %#x is not a 24-bit RGB value", color)
+	}
+	return b
+}
+
+// Field ajoute un champ pleine largeur
+func (b *EmbedBuilder) Field(name, value string) *EmbedBuilder {
+	return b.addField(EmbedField{Name: name, Value: value})
+}
+
+// InlineField ajoute un champ affiché en colonne
+func (b *EmbedBuilder) InlineField(name, value string) *EmbedBuilder {
+	return b.addField(EmbedField{Name: name, Value: value, Inline: true})
+}
+
+func (b *EmbedBuilder) addField(field EmbedField) *EmbedBuilder {
+	b.embed.Fields = append(b.embed.Fields, field)
+	if len(b.embed.Fields) == MaxEmbedFields+1 {
+		b.v.addf("fields", "more than %d fields", MaxEmbedFields)
+	}
+	field.validate(&b.v, fmt.Sprintf("fields[%d]", len(b.embed.Fields)-1))
+	return b
+}
+
+// Image définit l'image principale de l'embed
+func (b *EmbedBuilder) Image(url string) *EmbedBuilder {
+	b.embed.Image = Media(url)
+	return b
+}
+
+// Thumbnail définit la miniature de l'embed
+func (b *EmbedBuilder) Thumbnail(url string) *EmbedBuilder {
+	b.embed.Thumbnail = Media(url)
+	return b
+}
+
+// Author définit l'auteur de l'embed, url et iconURL sont facultatifs
+func (b *EmbedBuilder) Author(name, url, iconURL string) *EmbedBuilder {
+	b.embed.Author = &EmbedAuthor{Name: name, URL: url, IconURL: iconURL}
+	b.v.reset("author.name")
+	b.v.required("author.name", name)
+	b.v.maxLength("author.name", name, MaxEmbedAuthorNameLength)
+	return b
+}
+
+// Footer définit le pied de l'embed, iconURL est facultatif
+func (b *EmbedBuilder) Footer(text, iconURL string) *EmbedBuilder {
+	b.embed.Footer = &EmbedFooter{Text: text, IconURL: iconURL}
+	b.v.reset("footer.text")
+	b.v.required("footer.text", text)
+	b.v.maxLength("footer.text", text, MaxEmbedFooterLength)
+	return b
+}
+
+// Timestamp définit l'horodatage affiché en bas de l'embed
+func (b *EmbedBuilder) Timestamp(t time.Time) *EmbedBuilder {
+	b.embed.Timestamp = t
+	return b
+}
+
+// Err retourne les violations relevées jusqu'ici, ou nil
+func (b *EmbedBuilder) Err() error {
+	return b.v.err()
+}
+
+// Build retourne l'embed construit et une *ValidationError s'il ne respecte
+// pas les limites de Discord
+func (b *EmbedBuilder) Build() (DiscordEmbed, error) {
+	embed := b.embed
+	embed.Fields = append([]EmbedField(nil), b.embed.Fields...)
+	return embed, embed.Validate()
+}
+
+// MessageBuilder construit un DiscordPayload prêt pour SendCustomPayload en
+// validant les limites de Discord au fur et à mesure des appels
+type MessageBuilder struct {
+	payload DiscordPayload
+	v       validator
+}
+
+// NewMessage crée un nouveau constructeur de message
+func NewMessage() *MessageBuilder {
+	return &MessageBuilder{}
+}
+
+// Content définit le texte du message
+func (b *MessageBuilder) Content(content string) *MessageBuilder {
+	b.payload.Content = content
+	b.v.reset("content")
+	b.v.maxLength("content", content, MaxContentLength)
+	return b
+}
+
+// Username remplace le nom du webhook pour ce message
+func (b *MessageBuilder) Username(username string) *MessageBuilder {
+	b.payload.Username = username
+	b.v.reset("username")
+	b.v.maxLength("username", username, MaxUsernameLength)
+	return b
+}
+
+// Avatar remplace l'avatar du webhook pour ce message
+func (b *MessageBuilder) Avatar(url string) *MessageBuilder {
+	b.payload.Avatar = url
+	return b
+}
+
+// TTS active la lecture du message par synthèse vocale
+func (b *MessageBuilder) TTS(tts bool) *MessageBuilder {
+	b.payload.TTS = tts
+	return b
+}
+
+// Silent envoie le message sans notifier ses destinataires
+func (b *MessageBuilder) Silent() *MessageBuilder {
+	return b.Flags(FlagSuppressNotifications)
+}
+
+// SuppressEmbeds désactive les aperçus des liens du message
+func (b *MessageBuilder) SuppressEmbeds() *MessageBuilder {
+	return b.Flags(FlagSuppressEmbeds)
+}
+
+// Flags ajoute flags aux flags du message
+func (b *MessageBuilder) Flags(flags MessageFlags) *MessageBuilder {
+	b.payload.Flags |= flags
+	return b
+}
+
+// AllowedMentions restreint les mentions notifiées par le message
+func (b *MessageBuilder) AllowedMentions(mentions *AllowedMentions) *MessageBuilder {
+	b.payload.AllowedMentions = mentions
+	return b
+}
+
+// MentionUsers ajoute la mention des utilisateurs ids au texte du message et
+// les autorise explicitement à être notifiés
+func (b *MessageBuilder) MentionUsers(ids ...string) *MessageBuilder {
+	mentions := b.explicitMentions(MentionUsers)
+	for _, id := range ids {
+		b.appendContent(MentionUser(id))
+	}
+	mentions.AllowUsers(ids...)
+	return b
+}
+
+// MentionRoles ajoute la mention des rôles ids au texte du message et les
+// autorise explicitement à être notifiés
+func (b *MessageBuilder) MentionRoles(ids ...string) *MessageBuilder {
+	mentions := b.explicitMentions(MentionRoles)
+	for _, id := range ids {
+		b.appendContent(MentionRole(id))
+	}
+	mentions.AllowRoles(ids...)
+	return b
+}
+
+// explicitMentions retourne les mentions du message en retirant kind de Parse,
+// Discord refusant une liste explicite combinée au type correspondant
+func (b *MessageBuilder) explicitMentions(kind MentionType) *AllowedMentions {
+	mentions := NoMentions()
+	if current := b.payload.AllowedMentions; current != nil {
+		mentions.Users = append([]string(nil), current.Users...)
+		mentions.Roles = append([]string(nil), current.Roles...)
+		mentions.RepliedUser = current.RepliedUser
+		for _, t := range current.Parse {
+			if t != kind {
+				mentions.Parse = append(mentions.Parse, t)
+			}
+		}
+	}
+	b.payload.AllowedMentions = mentions
+	return mentions
+}
+
+func (b *MessageBuilder) appendContent(s string) {
+	if b.payload.Content != "" {
+		s = " " + s
+	}
+	b.Content(b.payload.Content + s)
+}
+
+// Embed ajoute l'embed construit par e au message
+func (b *MessageBuilder) Embed(e *EmbedBuilder) *MessageBuilder {
+	embed, _ := e.Build()
+	return b.AddEmbed(embed)
+}
+
+// AddEmbed ajoute un embed au message
+func (b *MessageBuilder) AddEmbed(embed DiscordEmbed) *MessageBuilder {
+	b.payload.Embeds = append(b.payload.Embeds, embed)
+	if len(b.payload.Embeds) == MaxEmbedsPerMessage+1 {
+		b.v.addf("embeds", "more than %d embeds", MaxEmbedsPerMessage)
+	}
+	embed.validate(&b.v, fmt.Sprintf("embeds[%d]", len(b.payload.Embeds)-1))
+	return b
+}
+
+// LinkButtons ajoute une ligne de boutons ouvrant des liens, ex:
+// LinkButtons(LinkButton("View logs", logsURL))
+func (b *MessageBuilder) LinkButtons(buttons ...Button) *MessageBuilder {
+	row := ActionRow{Components: make(Components, len(buttons))}
+	for i, button := range buttons {
+		row.Components[i] = button
+	}
+	return b.Components(row)
+}
+
+// Components ajoute des composants de premier niveau au message. Les
+// composants de mise en page nécessitent FlagIsComponentsV2, leur validation
+// dépendant des flags elle n'a lieu que dans Err et Build
+func (b *MessageBuilder) Components(components ...Component) *MessageBuilder {
+	b.payload.Components = append(b.payload.Components, components...)
+	return b
+}
+
+// Poll joint un sondage au message
+func (b *MessageBuilder) Poll(poll Poll) *MessageBuilder {
+	b.payload.Poll = &poll
+	b.v.reset("poll")
+	poll.validate(&b.v, "poll")
+	return b
+}
+
+// Attach joint le fichier situé à path, lu au moment de l'envoi
+func (b *MessageBuilder) Attach(path string) *MessageBuilder {
+	return b.addFile(Attachment{Name: filepath.Base(path), Path: path})
+}
+
+// AttachData joint un fichier nommé name dont le contenu est data
+func (b *MessageBuilder) AttachData(name string, data []byte) *MessageBuilder {
+	return b.addFile(Attachment{Name: name, Data: data})
+}
+
+func (b *MessageBuilder) addFile(file Attachment) *MessageBuilder {
+	b.payload.Files = append(b.payload.Files, file)
+	if len(b.payload.Files) == MaxFilesPerMessage+1 {
+		b.v.addf("files", "more than %d files", MaxFilesPerMessage)
+	}
+	return b
+}
+
+// Err retourne les violations relevées jusqu'ici, ou nil. Les composants sont
+// vérifiés avec les flags courants
+func (b *MessageBuilder) Err() error {
+	v := validator{issues: append([]ValidationIssue(nil), b.v.issues...)}
+	validateComponents(&v, b.payload.Components, b.payload.Flags)
+	return v.err()
+}
+
+// Build retourne le payload construit et une *ValidationError s'il ne respecte
+// pas les limites de Discord
+func (b *MessageBuilder) Build() (DiscordPayload, error) {
+	payload := b.payload
+	payload.Embeds = append([]DiscordEmbed(nil), b.payload.Embeds...)
+	payload.Files = append([]Attachment(nil), b.payload.Files...)
+	payload.Components = append(Components(nil), b.payload.Components...)
+	return payload, payload.Validate()
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	discordwebhook "github.com/peepsii/discord-webhook-go"
+)
+
+// Politiques de notification de exec
+const (
+	notifyAlways  = "always"
+	notifyFailure = "failure"
+	notifyChange  = "change"
+)
+
+// maxOutputExcerpt est la taille maximale de l'extrait de sortie affiché
+// dans la description de l'embed
+const maxOutputExcerpt = 3800
+
+// exitCommandNotRun est le code de sortie lorsque la commande n'a pas pu
+// être lancée, comme pour un shell
+const exitCommandNotRun = 127
+
+// exitStatusError transmet le code de sortie de la commande exécutée
+type exitStatusError struct {
+	code int
+}
+
+func (e exitStatusError) Error() string { return "exit status " + strconv.Itoa(e.code) }
+
+func runExec(args []string, stdio stdio) error {
+	fs := newFlagSet("exec", "[flags] -- <command> [args...]", stdio)
+	var client clientFlags
+	var name, on, stateFile string
+	var maxOutput int
+	var attach bool
+	client.register(fs)
+	fs.StringVar(&name, "name", "", "job name shown in the notification (default the command line)")
+	fs.StringVar(&on, "on", notifyAlways, "when to notify: always, failure or change (since the last run)")
+	fs.StringVar(&stateFile, "state-file", "", "file remembering the last outcome for --on change (default in the user cache directory)")
+	fs.IntVar(&maxOutput, "max-output", 1<<20, "bytes of combined output kept, the most recent ones")
+	fs.BoolVar(&attach, "attach", true, "attach the captured output as a file")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	command := fs.Args()
+	if len(command) == 0 {
+		return usagef("missing command, use discord-webhook exec [flags] -- <command> [args...]")
+	}
+	if on != notifyAlways && on != notifyFailure && on != notifyChange {
+		return usagef("invalid --on %q, want always, failure or change", on)
+	}
+	if maxOutput <= 0 {
+		return usagef("invalid --max-output %d", maxOutput)
+	}
+
+	c, err := client.client(stdio)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = strings.Join(command, " ")
+	}
+
+	result := execute(command, maxOutput, stdio)
+	notify := on == notifyAlways || (on == notifyFailure && result.exitCode != 0)
+	outcome := outcomeOf(result.exitCode == 0)
+	if on == notifyChange {
+		if stateFile == "" {
+			stateFile = defaultStateFile(command)
+		}
+		notify = outcome != previousOutcome(stateFile)
+	}
+
+	if notify {
+		// L'état n'est pas enregistré si l'envoi échoue, le changement étant
+		// alors notifié à la prochaine exécution
+		if err := c.SendCustomPayload(result.payload(name, attach)); err != nil {
+			if result.exitCode != 0 {
+				fmt.Fprintf(stdio.err, "discord-webhook: %v\n", err)
+				return exitStatusError{result.exitCode}
+			}
+			return err
+		}
+	}
+	if on == notifyChange {
+		if err := saveOutcome(stateFile, outcome); err != nil {
+			fmt.Fprintf(stdio.err, "discord-webhook: %v\n", err)
+		}
+	}
+	if result.exitCode != 0 {
+		return exitStatusError{result.exitCode}
+	}
+	return nil
+}
+
+// execResult est le résultat d'une commande exécutée par exec
+type execResult struct {
+	command   []string
+	exitCode  int
+	signal    syscall.Signal
+	duration  time.Duration
+	output    []byte
+	truncated int64
+	started   time.Time
+}
+
+// execute lance la commande en recopiant sa sortie vers stdio tout en
+// conservant les maxOutput derniers octets
+func execute(command []string, maxOutput int, stdio stdio) execResult {
+	output := &tailBuffer{max: maxOutput}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = stdio.in
+	cmd.Stdout = io.MultiWriter(stdio.out, output)
+	cmd.Stderr = io.MultiWriter(stdio.err, output)
+
+	result := execResult{command: command, started: time.Now()}
+	err := cmd.Run()
+	result.duration = time.Since(result.started)
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		result.exitCode = exitErr.ExitCode()
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			// Processus interrompu par un signal, code 128+n comme un shell
+			result.signal = status.Signal()
+			result.exitCode = 128 + int(result.signal)
+			fmt.Fprintf(output, "\n%v\n", err)
+		}
+	default:
+		result.exitCode = exitCommandNotRun
+		fmt.Fprintf(output, "%v\n", err)
+		fmt.Fprintf(stdio.err, "discord-webhook: %v\n", err)
+	}
+	result.output, result.truncated = output.bytes()
+	return result
+}
+
+// payload construit la notification du résultat
+func (r execResult) payload(name string, attach bool) discordwebhook.DiscordPayload {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	title, color := "✅ "+name+" succeeded", discordwebhook.ColorSuccess
+	if r.exitCode != 0 {
+		title, color = "❌ "+name+" failed", discordwebhook.ColorFailure
+	}
+	exitCode := strconv.Itoa(r.exitCode)
+	if r.signal != 0 {
+		title = fmt.Sprintf("❌ %s %v", name, r.signal)
+		exitCode += fmt.Sprintf(" (signal %d)", int(r.signal))
+	}
+
+	command, _ := discordwebhook.TailCodeBlock(strings.Join(r.command, " "), discordwebhook.MaxEmbedFieldValueLength)
+
+	b := discordwebhook.NewEmbed().
+		Title(discordwebhook.Truncate(title, discordwebhook.MaxEmbedTitleLength)).
+		Color(color).
+		InlineField("Exit code", exitCode).
+		InlineField("Host", host).
+		InlineField("Duration", r.duration.Round(time.Millisecond).String()).
+		Field("Command", command).
+		Timestamp(r.started)
+	if excerpt := r.excerpt(); excerpt != "" {
+		b.Description(excerpt)
+	}
+	if r.truncated > 0 {
+		b.Footer(fmt.Sprintf("Output truncated, %d earlier bytes dropped", r.truncated), "")
+	}
+	embed, _ := b.Build()
+
+	payload := discordwebhook.DiscordPayload{Embeds: []discordwebhook.DiscordEmbed{embed}}
+	if attach && len(r.output) > 0 {
+		payload.Files = []discordwebhook.Attachment{{Name: "output.txt", Data: r.output}}
+	}
+	return payload
+}
+
+// excerpt retourne la fin de la sortie dans un bloc de code
+func (r execResult) excerpt() string {
+	text := strings.TrimRight(strings.ToValidUTF8(string(r.output), "�"), "\n")
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	excerpt, _ := discordwebhook.TailCodeBlock(text, maxOutputExcerpt)
+	return excerpt
+}
+
+// defaultStateFile retourne le fichier d'état propre à la commande
+func defaultStateFile(command []string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	sum := sha256.Sum256([]byte(strings.Join(command, "\x00")))
+	return filepath.Join(dir, "discord-webhook", "exec-"+hex.EncodeToString(sum[:8])+".state")
+}
+
+// outcomeOf retourne le résultat enregistré dans le fichier d'état
+func outcomeOf(success bool) string {
+	if success {
+		return "success"
+	}
+	return "failure"
+}
+
+// previousOutcome retourne le résultat enregistré dans path. Sans résultat
+// précédent, la commande est considérée comme ayant réussi
+func previousOutcome(path string) string {
+	if data, err := os.ReadFile(path); err == nil {
+		return strings.TrimSpace(string(data))
+	}
+	return outcomeOf(true)
+}
+
+// saveOutcome enregistre outcome dans path
+func saveOutcome(path, outcome string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(outcome+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	return nil
+}
+
+// tailBuffer conserve les max derniers octets écrits
+type tailBuffer struct {
+	mu      sync.Mutex
+	max     int
+	buf     []byte
+	dropped int64
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	// Ne compacter qu'au double de la taille pour amortir les copies
+	if len(t.buf) > 2*t.max {
+		drop := len(t.buf) - t.max
+		t.dropped += int64(drop)
+		t.buf = append(t.buf[:0], t.buf[drop:]...)
+	}
+	return len(p), nil
+}
+
+// bytes retourne les octets conservés et le nombre d'octets abandonnés
+func (t *tailBuffer) bytes() ([]byte, int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	buf, dropped := t.buf, t.dropped
+	if len(buf) > t.max {
+		dropped += int64(len(buf) - t.max)
+		buf = buf[len(buf)-t.max:]
+	}
+	return append([]byte(nil), buf...), dropped
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/peepsii/discord-webhook-go/gotestreport"
+)
+
+func runGotest(args []string, stdio stdio) error {
+	fs := newFlagSet("gotest", "[flags] [file]", stdio)
+	var client clientFlags
+	var title, on string
+	var tee bool
+	client.register(fs)
+	fs.StringVar(&title, "title", gotestreport.DefaultTitle, "title of the summary")
+	fs.StringVar(&on, "on", notifyAlways, "when to notify: always or failure")
+	fs.BoolVar(&tee, "tee", false, "copy the input to stdout")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return usagef("unexpected arguments %q", fs.Args()[1:])
+	}
+	if on != notifyAlways && on != notifyFailure {
+		return usagef("invalid --on %q, want always or failure", on)
+	}
+
+	c, err := client.client(stdio)
+	if err != nil {
+		return err
+	}
+
+	var input io.Reader = stdio.in
+	if name := fs.Arg(0); name != "" && name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return fmt.Errorf("failed to read test output: %w", err)
+		}
+		defer f.Close()
+		input = f
+	}
+	if tee {
+		input = io.TeeReader(input, stdio.out)
+	}
+
+	report, err := gotestreport.Parse(input)
+	if errors.Is(err, gotestreport.ErrNoEvents) {
+		return usagef("%v, run go test with -json", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	if on == notifyAlways || !report.OK() {
+		if err := c.SendCustomPayload(report.Payload(title)); err != nil {
+			if !report.OK() {
+				fmt.Fprintf(stdio.err, "discord-webhook: %v\n", err)
+				return exitStatusError{exitError}
+			}
+			return err
+		}
+	}
+	if !report.OK() {
+		return exitStatusError{exitError}
+	}
+	return nil
+}
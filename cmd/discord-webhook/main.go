@@ -0,0 +1,272 @@
+// Command discord-webhook envoie des messages à un webhook Discord depuis des
+// scripts shell.
+//
+// Usage :
+//
+//	discord-webhook send [flags] [content...]
+//	discord-webhook edit <message-id> [flags] [content...]
+//	discord-webhook delete <message-id> [flags]
+//	discord-webhook get <message-id> [flags]
+//	discord-webhook info [flags]
+//	discord-webhook exec [flags] -- <command> [args...]
+//	go test -json ./... | discord-webhook gotest [flags]
+//
+// L'URL du webhook est lue depuis --url, le fichier --url-file ou la variable
+// DISCORD_WEBHOOK_URL. --format json produit une sortie JSON pour les scripts.
+// Le code de sortie distingue les erreurs : 2 pour un usage invalide, 3 pour
+// un payload rejeté par la validation, 4 pour une limite de débit persistante
+// et 5 pour une erreur retournée par Discord. exec retourne le code de sortie
+// de la commande exécutée et gotest retourne 1 lorsque des tests ont échoué
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	discordwebhook "github.com/peepsii/discord-webhook-go"
+)
+
+// Codes de sortie
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitUsage       = 2
+	exitValidation  = 3
+	exitRateLimited = 4
+	exitAPI         = 5
+)
+
+// envWebhookURL est la variable d'environnement contenant l'URL du webhook
+const envWebhookURL = "DISCORD_WEBHOOK_URL"
+
+// command est une sous-commande
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdio stdio) error
+}
+
+// stdio regroupe les flux de la commande
+type stdio struct {
+	in       io.Reader
+	out, err io.Writer
+}
+
+var commands = []command{
+	{"send", "send a message", runSend},
+	{"edit", "edit a message sent by the webhook", runEdit},
+	{"delete", "delete a message sent by the webhook", runDelete},
+	{"get", "print a message sent by the webhook", runGet},
+	{"info", "print the webhook name, channel and guild", runInfo},
+	{"exec", "run a command and report its outcome", runExec},
+	{"gotest", "report go test -json results", runGotest},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], stdio{os.Stdin, os.Stdout, os.Stderr}))
+}
+
+func run(args []string, stdio stdio) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		printUsage(stdio.err)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return exitCode(cmd.run(args[1:], stdio), stdio.err)
+		}
+	}
+	fmt.Fprintf(stdio.err, "discord-webhook: unknown command %q\n", args[0])
+	printUsage(stdio.err)
+	return exitUsage
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: discord-webhook <command> [flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nRun discord-webhook <command> -h for the flags of a command.")
+}
+
+// usageError signale des arguments invalides
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string { return e.err.Error() }
+
+func usagef(format string, args ...any) error {
+	return usageError{fmt.Errorf(format, args...)}
+}
+
+// exitCode affiche err et retourne le code de sortie correspondant
+func exitCode(err error, stderr io.Writer) int {
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+
+	// Les erreurs de flag ont déjà été affichées par le FlagSet
+	if errors.Is(err, errFlagParse) {
+		return exitUsage
+	}
+	var statusErr exitStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code
+	}
+	fmt.Fprintf(stderr, "discord-webhook: %v\n", err)
+
+	var usageErr usageError
+	var validationErr *discordwebhook.ValidationError
+	var rateLimitErr *discordwebhook.RateLimitError
+	var apiErr *discordwebhook.APIError
+
+	switch {
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case errors.As(err, &validationErr):
+		return exitValidation
+	case errors.As(err, &rateLimitErr):
+		return exitRateLimited
+	case errors.As(err, &apiErr):
+		return exitAPI
+	default:
+		return exitError
+	}
+}
+
+// errFlagParse signale une erreur déjà affichée par le FlagSet
+var errFlagParse = errors.New("invalid flags")
+
+// parseFlags analyse args, les erreurs étant affichées par le FlagSet
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errFlagParse
+	}
+	return nil
+}
+
+// newFlagSet crée le FlagSet d'une sous-commande
+func newFlagSet(name, usage string, stdio stdio) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stdio.err)
+	fs.Usage = func() {
+		fmt.Fprintf(stdio.err, "Usage: discord-webhook %s %s\n\nFlags:\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// clientFlags sont les flags communs de configuration du client
+type clientFlags struct {
+	url        string
+	urlFile    string
+	baseURL    string
+	thread     string
+	username   string
+	avatar     string
+	timeout    time.Duration
+	maxRetries int
+	dryRun     bool
+}
+
+func (f *clientFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.url, "url", "", "webhook URL (default $"+envWebhookURL+")")
+	fs.StringVar(&f.urlFile, "url-file", "", "read the webhook URL from a file")
+	fs.StringVar(&f.baseURL, "base-url", "", "send requests to this base URL instead of https://discord.com")
+	fs.StringVar(&f.thread, "thread", "", "ID of the thread to post in")
+	fs.StringVar(&f.username, "username", "", "override the webhook username")
+	fs.StringVar(&f.avatar, "avatar", "", "override the webhook avatar URL")
+	fs.DurationVar(&f.timeout, "timeout", discordwebhook.DefaultTimeout, "HTTP request timeout")
+	fs.IntVar(&f.maxRetries, "max-retries", 3, "retries after a rate limit before giving up")
+	fs.BoolVar(&f.dryRun, "dry-run", false, "print the requests instead of sending them")
+}
+
+// client construit le client à partir des flags
+func (f *clientFlags) client(stdio stdio) (*discordwebhook.Client, error) {
+	raw := strings.TrimSpace(f.url)
+	if raw == "" && f.urlFile != "" {
+		data, err := os.ReadFile(f.urlFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read webhook URL: %w", err)
+		}
+		raw = strings.TrimSpace(string(data))
+	}
+	if raw == "" {
+		raw = strings.TrimSpace(os.Getenv(envWebhookURL))
+	}
+	if raw == "" {
+		return nil, usagef("missing webhook URL, use --url, --url-file or $%s", envWebhookURL)
+	}
+	if _, err := discordwebhook.ParseWebhookURL(raw); err != nil {
+		return nil, usageError{err}
+	}
+	if f.maxRetries < 0 {
+		return nil, usagef("invalid --max-retries %d", f.maxRetries)
+	}
+
+	options := []discordwebhook.Option{
+		discordwebhook.WebhookOptions{Username: f.username, Avatar: f.avatar, ThreadID: f.thread},
+		discordwebhook.WithTimeout(f.timeout),
+		discordwebhook.WithMaxRetries(f.maxRetries),
+		discordwebhook.WithUserAgent(discordwebhook.DefaultUserAgent + " discord-webhook-cli"),
+	}
+	if f.baseURL != "" {
+		options = append(options, discordwebhook.WithBaseURL(f.baseURL))
+	}
+	if f.dryRun {
+		options = append(options, discordwebhook.WithDryRun(stdio.out))
+	}
+	return discordwebhook.NewClient(raw, options...), nil
+}
+
+// outputFlags choisissent le format de sortie
+type outputFlags struct {
+	format string
+}
+
+func (f *outputFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.format, "format", "text", "output format, text or json")
+}
+
+func (f *outputFlags) validate() error {
+	if f.format != "text" && f.format != "json" {
+		return usagef("invalid --format %q, want text or json", f.format)
+	}
+	return nil
+}
+
+// print écrit v en JSON, ou text() au format texte
+func (f *outputFlags) print(w io.Writer, v any, text func() string) error {
+	if f.format != "json" {
+		_, err := fmt.Fprintln(w, text())
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// stringsFlag est un flag répétable
+type stringsFlag []string
+
+func (s *stringsFlag) String() string { return strings.Join(*s, ", ") }
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	discordwebhook "github.com/peepsii/discord-webhook-go"
+)
+
+// messageID extrait l'ID de message placé avant, entre ou après les flags
+func messageID(fs *flag.FlagSet, args []string) (string, []string, error) {
+	var id string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		id, args = args[0], args[1:]
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return "", nil, err
+	}
+
+	if id == "" {
+		if fs.NArg() == 0 {
+			return "", nil, usagef("missing message ID")
+		}
+		// L'analyse s'arrête à l'ID, les flags qui le suivent restent à lire
+		id = fs.Arg(0)
+		if err := parseFlags(fs, fs.Args()[1:]); err != nil {
+			return "", nil, err
+		}
+	}
+	return id, fs.Args(), nil
+}
+
+func runEdit(args []string, stdio stdio) error {
+	fs := newFlagSet("edit", "<message-id> [flags] [content...]", stdio)
+	var client clientFlags
+	var message messageFlags
+	var output outputFlags
+	client.register(fs)
+	message.register(fs)
+	output.register(fs)
+	id, rest, err := messageID(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := output.validate(); err != nil {
+		return err
+	}
+
+	c, err := client.client(stdio)
+	if err != nil {
+		return err
+	}
+	payload, err := message.payload(rest, stdio.in)
+	if err != nil {
+		return err
+	}
+
+	edited, err := c.EditMessage(id, payload)
+	if err != nil {
+		return err
+	}
+	return output.print(stdio.out, edited, func() string { return edited.ID })
+}
+
+func runDelete(args []string, stdio stdio) error {
+	fs := newFlagSet("delete", "<message-id> [flags]", stdio)
+	var client clientFlags
+	client.register(fs)
+	id, rest, err := messageID(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("unexpected arguments %q", rest)
+	}
+
+	c, err := client.client(stdio)
+	if err != nil {
+		return err
+	}
+	return c.DeleteMessage(id)
+}
+
+func runGet(args []string, stdio stdio) error {
+	fs := newFlagSet("get", "<message-id> [flags]", stdio)
+	var client clientFlags
+	var output outputFlags
+	client.register(fs)
+	output.register(fs)
+	id, rest, err := messageID(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("unexpected arguments %q", rest)
+	}
+	if err := output.validate(); err != nil {
+		return err
+	}
+
+	c, err := client.client(stdio)
+	if err != nil {
+		return err
+	}
+	message, err := c.GetMessage(id)
+	if err != nil {
+		return err
+	}
+	return output.print(stdio.out, message, func() string { return formatMessage(message) })
+}
+
+func runInfo(args []string, stdio stdio) error {
+	fs := newFlagSet("info", "[flags]", stdio)
+	var client clientFlags
+	var output outputFlags
+	client.register(fs)
+	output.register(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("unexpected arguments %q", fs.Args())
+	}
+	if err := output.validate(); err != nil {
+		return err
+	}
+
+	c, err := client.client(stdio)
+	if err != nil {
+		return err
+	}
+	webhook, err := c.GetWebhook()
+	if err != nil {
+		return err
+	}
+	return output.print(stdio.out, webhook, func() string {
+		return formatFields(
+			"Name", webhook.Name,
+			"ID", webhook.ID,
+			"Channel", webhook.ChannelID,
+			"Guild", webhook.GuildID,
+			"Avatar", webhook.AvatarURL(),
+		)
+	})
+}
+
+// formatMessage résume un message au format texte
+func formatMessage(message *discordwebhook.Message) string {
+	edited := ""
+	if message.EditedTimestamp != nil {
+		edited = message.EditedTimestamp.Format(time.RFC3339)
+	}
+	titles := make([]string, len(message.Embeds))
+	for i, embed := range message.Embeds {
+		titles[i] = embed.Title
+	}
+	files := make([]string, len(message.Attachments))
+	for i, attachment := range message.Attachments {
+		files[i] = attachment.Filename
+	}
+
+	return formatFields(
+		"ID", message.ID,
+		"Channel", message.ChannelID,
+		"Timestamp", message.Timestamp.Format(time.RFC3339),
+		"Edited", edited,
+		"Content", message.Content,
+		"Embeds", strings.Join(titles, ", "),
+		"Attachments", strings.Join(files, ", "),
+	)
+}
+
+// formatFields aligne des paires libellé/valeur, les valeurs vides étant omises
+func formatFields(pairs ...string) string {
+	var b strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%-12s %s", pairs[i]+":", pairs[i+1])
+	}
+	return b.String()
+}
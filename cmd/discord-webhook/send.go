@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	discordwebhook "github.com/peepsii/discord-webhook-go"
+)
+
+// messageFlags sont les flags décrivant le contenu d'un message
+type messageFlags struct {
+	jsonFile    string
+	title       string
+	description string
+	embedURL    string
+	color       string
+	footer      string
+	fields      stringsFlag
+	inline      stringsFlag
+	files       stringsFlag
+}
+
+func (f *messageFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.jsonFile, "json", "", "read the payload from a JSON file, - for stdin")
+	fs.StringVar(&f.title, "embed-title", "", "embed title")
+	fs.StringVar(&f.description, "embed-description", "", "embed description")
+	fs.StringVar(&f.embedURL, "embed-url", "", "embed title link")
+	fs.StringVar(&f.color, "color", "", "embed color, decimal, 0xrrggbb or #rrggbb")
+	fs.StringVar(&f.footer, "embed-footer", "", "embed footer text")
+	fs.Var(&f.fields, "field", "embed field as name=value (repeatable)")
+	fs.Var(&f.inline, "inline-field", "inline embed field as name=value (repeatable)")
+	fs.Var(&f.files, "file", "attach a file (repeatable)")
+}
+
+// hasEmbed indique si des flags d'embed sont définis
+func (f *messageFlags) hasEmbed() bool {
+	return f.title != "" || f.description != "" || f.embedURL != "" || f.color != "" ||
+		f.footer != "" || len(f.fields) > 0 || len(f.inline) > 0
+}
+
+// payload construit le payload à partir des flags et du contenu args, lu sur
+// stdin lorsqu'il vaut "-" ou qu'il est vide et que stdin n'est pas un terminal
+func (f *messageFlags) payload(args []string, stdin io.Reader) (discordwebhook.DiscordPayload, error) {
+	var payload discordwebhook.DiscordPayload
+	if f.jsonFile != "" {
+		data, err := readInput(f.jsonFile, stdin)
+		if err != nil {
+			return payload, fmt.Errorf("failed to read payload: %w", err)
+		}
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return payload, usagef("invalid JSON payload: %v", err)
+		}
+	}
+
+	content := strings.Join(args, " ")
+	readStdin := content == "-" ||
+		(content == "" && f.jsonFile != "-" && payload.Content == "" && !f.hasEmbed() && len(f.files) == 0 && isPiped(stdin))
+	if readStdin {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return payload, fmt.Errorf("failed to read stdin: %w", err)
+		}
+		content = strings.TrimRight(string(data), "\n")
+	}
+	if content != "" {
+		payload.Content = content
+	}
+
+	if f.hasEmbed() {
+		embed, err := f.embed()
+		if err != nil {
+			return payload, err
+		}
+		payload.Embeds = append(payload.Embeds, embed)
+	}
+	for _, path := range f.files {
+		payload.Files = append(payload.Files, discordwebhook.Attachment{Name: filepath.Base(path), Path: path})
+	}
+	return payload, nil
+}
+
+// embed construit l'embed décrit par les flags
+func (f *messageFlags) embed() (discordwebhook.DiscordEmbed, error) {
+	b := discordwebhook.NewEmbed().Title(f.title).Description(f.description).URL(f.embedURL)
+	if f.color != "" {
+		color, err := discordwebhook.ParseColor(f.color)
+		if err != nil {
+			return discordwebhook.DiscordEmbed{}, usageError{err}
+		}
+		b.Color(color)
+	}
+	if f.footer != "" {
+		b.Footer(f.footer, "")
+	}
+	for _, fields := range []struct {
+		values stringsFlag
+		add    func(name, value string) *discordwebhook.EmbedBuilder
+	}{{f.fields, b.Field}, {f.inline, b.InlineField}} {
+		for _, field := range fields.values {
+			name, value, ok := strings.Cut(field, "=")
+			if !ok {
+				return discordwebhook.DiscordEmbed{}, usagef("invalid field %q, want name=value", field)
+			}
+			fields.add(name, value)
+		}
+	}
+	return b.Build()
+}
+
+func runSend(args []string, stdio stdio) error {
+	fs := newFlagSet("send", "[flags] [content...]", stdio)
+	var client clientFlags
+	var message messageFlags
+	var output outputFlags
+	var wait, tts, silent bool
+	client.register(fs)
+	message.register(fs)
+	output.register(fs)
+	fs.BoolVar(&tts, "tts", false, "send as text-to-speech")
+	fs.BoolVar(&silent, "silent", false, "do not send push notifications")
+	fs.BoolVar(&wait, "wait", false, "wait for the message and print its ID, or the message with --format json")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := output.validate(); err != nil {
+		return err
+	}
+
+	c, err := client.client(stdio)
+	if err != nil {
+		return err
+	}
+	payload, err := message.payload(fs.Args(), stdio.in)
+	if err != nil {
+		return err
+	}
+	payload.TTS = payload.TTS || tts
+	if silent {
+		payload.Flags |= discordwebhook.FlagSuppressNotifications
+	}
+
+	if !wait {
+		return c.SendCustomPayload(payload)
+	}
+	sent, err := c.SendAndWait(payload)
+	if err != nil {
+		return err
+	}
+	return output.print(stdio.out, sent, func() string { return sent.ID })
+}
+
+// readInput lit le fichier path, ou stdin lorsqu'il vaut "-"
+func readInput(path string, stdin io.Reader) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// isPiped indique si r est un fichier ou un pipe plutôt qu'un terminal
+func isPiped(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return r != nil
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
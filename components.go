@@ -0,0 +1,543 @@
+package discordwebhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Limites imposées par Discord sur les composants d'un message
+const (
+	MaxActionRows             = 5
+	MaxActionRowComponents    = 5
+	MaxComponents             = 40
+	MaxSectionTextDisplays    = 3
+	MaxMediaGalleryItems      = 10
+	MaxButtonLabelLength      = 80
+	MaxCustomIDLength         = 100
+	MaxComponentsTextLength   = 4000
+	MaxMediaDescriptionLength = 1024
+)
+
+// ComponentType représente le type d'un composant de message
+type ComponentType int
+
+const (
+	ComponentActionRow    ComponentType = 1
+	ComponentButton       ComponentType = 2
+	ComponentSection      ComponentType = 9
+	ComponentTextDisplay  ComponentType = 10
+	ComponentThumbnail    ComponentType = 11
+	ComponentMediaGallery ComponentType = 12
+	ComponentFile         ComponentType = 13
+	ComponentSeparator    ComponentType = 14
+	ComponentContainer    ComponentType = 17
+)
+
+var componentNames = map[ComponentType]string{
+	ComponentActionRow:    "action row",
+	ComponentButton:       "button",
+	ComponentSection:      "section",
+	ComponentTextDisplay:  "text display",
+	ComponentThumbnail:    "thumbnail",
+	ComponentMediaGallery: "media gallery",
+	ComponentFile:         "file",
+	ComponentSeparator:    "separator",
+	ComponentContainer:    "container",
+}
+
+func (t ComponentType) String() string {
+	if name, ok := componentNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("component type %d", int(t))
+}
+
+// isLayout indique si le type nécessite FlagIsComponentsV2
+func (t ComponentType) isLayout() bool {
+	return t != ComponentActionRow && t != ComponentButton
+}
+
+// Component est implémenté par les composants de message : ActionRow, Button,
+// Section, TextDisplay, ThumbnailComponent, MediaGallery, FileComponent,
+// Separator et Container
+type Component interface {
+	Type() ComponentType
+	validate(v *validator, path string)
+	// children retourne les composants imbriqués, accessoire compris
+	children() Components
+}
+
+// Components est une liste de composants capable de se désérialiser
+type Components []Component
+
+// UnmarshalJSON désérialise chaque composant selon son champ type
+func (c *Components) UnmarshalJSON(data []byte) error {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return err
+	}
+
+	components := make(Components, len(raws))
+	for i, raw := range raws {
+		component, err := unmarshalComponent(raw)
+		if err != nil {
+			return err
+		}
+		components[i] = component
+	}
+	*c = components
+	return nil
+}
+
+func unmarshalComponent(data []byte) (Component, error) {
+	var header struct {
+		Type ComponentType `json:"type"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+
+	var component Component
+	switch header.Type {
+	case ComponentActionRow:
+		component = &ActionRow{}
+	case ComponentButton:
+		component = &Button{}
+	case ComponentSection:
+		component = &Section{}
+	case ComponentTextDisplay:
+		component = &TextDisplay{}
+	case ComponentThumbnail:
+		component = &ThumbnailComponent{}
+	case ComponentMediaGallery:
+		component = &MediaGallery{}
+	case ComponentFile:
+		component = &FileComponent{}
+	case ComponentSeparator:
+		component = &Separator{}
+	case ComponentContainer:
+		component = &Container{}
+	default:
+		return RawComponent{ComponentType: header.Type, Data: append(json.RawMessage(nil), data...)}, nil
+	}
+
+	if err := json.Unmarshal(data, component); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", header.Type, err)
+	}
+	return component, nil
+}
+
+// marshalComponent sérialise v en y ajoutant le champ type
+func marshalComponent(t ComponentType, v any) ([]byte, error) {
+	fields, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if string(fields) == "{}" {
+		return []byte(fmt.Sprintf(`{"type":%d}`, t)), nil
+	}
+	return []byte(fmt.Sprintf(`{"type":%d,%s`, t, fields[1:])), nil
+}
+
+// Emoji représente un emoji standard (Name) ou personnalisé (ID)
+type Emoji struct {
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Animated bool   `json:"animated,omitempty"`
+}
+
+// UnfurledMedia référence un média par URL, ou un fichier joint au message
+// par "attachment://<nom>"
+type UnfurledMedia struct {
+	URL string `json:"url"`
+}
+
+// ActionRow regroupe jusqu'à cinq boutons sur une ligne
+type ActionRow struct {
+	ID         int        `json:"id,omitempty"`
+	Components Components `json:"components"`
+}
+
+func (ActionRow) Type() ComponentType { return ComponentActionRow }
+
+func (r ActionRow) children() Components { return r.Components }
+
+func (r ActionRow) MarshalJSON() ([]byte, error) {
+	type actionRow ActionRow
+	return marshalComponent(r.Type(), actionRow(r))
+}
+
+func (r ActionRow) validate(v *validator, path string) {
+	if len(r.Components) == 0 || len(r.Components) > MaxActionRowComponents {
+		v.addf(joinPath(path, "components"), "action rows must contain 1 to %d components", MaxActionRowComponents)
+	}
+	validateChildren(v, joinPath(path, "components"), r.Components, ComponentButton)
+}
+
+// ButtonStyle représente le style d'un bouton
+type ButtonStyle int
+
+const (
+	ButtonPrimary   ButtonStyle = 1
+	ButtonSecondary ButtonStyle = 2
+	ButtonSuccess   ButtonStyle = 3
+	ButtonDanger    ButtonStyle = 4
+	// ButtonLink ouvre URL, seul style utilisable par un webhook qui
+	// n'appartient pas à une application
+	ButtonLink ButtonStyle = 5
+)
+
+// Button représente un bouton, placé dans une ActionRow ou en accessoire
+// d'une Section
+type Button struct {
+	ID       int         `json:"id,omitempty"`
+	Style    ButtonStyle `json:"style"`
+	Label    string      `json:"label,omitempty"`
+	Emoji    *Emoji      `json:"emoji,omitempty"`
+	URL      string      `json:"url,omitempty"`
+	CustomID string      `json:"custom_id,omitempty"`
+	Disabled bool        `json:"disabled,omitempty"`
+}
+
+// LinkButton retourne un bouton ouvrant url
+func LinkButton(label, url string) Button {
+	return Button{Style: ButtonLink, Label: label, URL: url}
+}
+
+func (Button) Type() ComponentType { return ComponentButton }
+
+func (Button) children() Components { return nil }
+
+func (b Button) MarshalJSON() ([]byte, error) {
+	type button Button
+	return marshalComponent(b.Type(), button(b))
+}
+
+func (b Button) validate(v *validator, path string) {
+	if b.Label == "" && b.Emoji == nil {
+		v.addf(path, "buttons must have a label or an emoji")
+	}
+	v.maxLength(joinPath(path, "label"), b.Label, MaxButtonLabelLength)
+
+	switch {
+	case b.Style == ButtonLink:
+		v.required(joinPath(path, "url"), b.URL)
+		if b.CustomID != "" {
+			v.addf(joinPath(path, "custom_id"), "link buttons cannot have a custom_id")
+		}
+	case b.Style >= ButtonPrimary && b.Style <= ButtonDanger:
+		v.required(joinPath(path, "custom_id"), b.CustomID)
+		v.maxLength(joinPath(path, "custom_id"), b.CustomID, MaxCustomIDLength)
+		if b.URL != "" {
+			v.addf(joinPath(path, "url"), "only link buttons can have a url")
+		}
+	default:
+		v.addf(joinPath(path, "style"), "unknown button style %d", b.Style)
+	}
+}
+
+// Section associe jusqu'à trois TextDisplay à un accessoire, Button ou
+// ThumbnailComponent
+type Section struct {
+	ID         int        `json:"id,omitempty"`
+	Components Components `json:"components"`
+	Accessory  Component  `json:"accessory"`
+}
+
+func (Section) Type() ComponentType { return ComponentSection }
+
+func (s Section) children() Components {
+	return append(s.Components[:len(s.Components):len(s.Components)], s.Accessory)
+}
+
+func (s Section) MarshalJSON() ([]byte, error) {
+	type section Section
+	return marshalComponent(s.Type(), section(s))
+}
+
+func (s *Section) UnmarshalJSON(data []byte) error {
+	type section Section
+	aux := struct {
+		*section
+		Accessory json.RawMessage `json:"accessory"`
+	}{section: (*section)(s)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	s.Accessory = nil
+	if len(aux.Accessory) > 0 && string(aux.Accessory) != "null" {
+		accessory, err := unmarshalComponent(aux.Accessory)
+		if err != nil {
+			return err
+		}
+		s.Accessory = accessory
+	}
+	return nil
+}
+
+func (s Section) validate(v *validator, path string) {
+	if len(s.Components) == 0 || len(s.Components) > MaxSectionTextDisplays {
+		v.addf(joinPath(path, "components"), "sections must contain 1 to %d text displays", MaxSectionTextDisplays)
+	}
+	validateChildren(v, joinPath(path, "components"), s.Components, ComponentTextDisplay)
+
+	if s.Accessory == nil {
+		v.addf(joinPath(path, "accessory"), "sections must have an accessory")
+		return
+	}
+	validateChild(v, joinPath(path, "accessory"), s.Accessory, ComponentButton, ComponentThumbnail)
+}
+
+// TextDisplay affiche un texte au format Markdown
+type TextDisplay struct {
+	ID      int    `json:"id,omitempty"`
+	Content string `json:"content"`
+}
+
+func (TextDisplay) Type() ComponentType { return ComponentTextDisplay }
+
+func (TextDisplay) children() Components { return nil }
+
+func (t TextDisplay) text() string { return t.Content }
+
+func (t TextDisplay) MarshalJSON() ([]byte, error) {
+	type textDisplay TextDisplay
+	return marshalComponent(t.Type(), textDisplay(t))
+}
+
+func (t TextDisplay) validate(v *validator, path string) {
+	v.required(joinPath(path, "content"), t.Content)
+}
+
+// ThumbnailComponent affiche une miniature en accessoire d'une Section
+type ThumbnailComponent struct {
+	ID          int           `json:"id,omitempty"`
+	Media       UnfurledMedia `json:"media"`
+	Description string        `json:"description,omitempty"`
+	Spoiler     bool          `json:"spoiler,omitempty"`
+}
+
+func (ThumbnailComponent) Type() ComponentType { return ComponentThumbnail }
+
+func (ThumbnailComponent) children() Components { return nil }
+
+func (t ThumbnailComponent) MarshalJSON() ([]byte, error) {
+	type thumbnail ThumbnailComponent
+	return marshalComponent(t.Type(), thumbnail(t))
+}
+
+func (t ThumbnailComponent) validate(v *validator, path string) {
+	v.required(joinPath(path, "media.url"), t.Media.URL)
+	v.maxLength(joinPath(path, "description"), t.Description, MaxMediaDescriptionLength)
+}
+
+// MediaGallery affiche jusqu'à dix images ou vidéos
+type MediaGallery struct {
+	ID    int                `json:"id,omitempty"`
+	Items []MediaGalleryItem `json:"items"`
+}
+
+// MediaGalleryItem représente un média d'une MediaGallery
+type MediaGalleryItem struct {
+	Media       UnfurledMedia `json:"media"`
+	Description string        `json:"description,omitempty"`
+	Spoiler     bool          `json:"spoiler,omitempty"`
+}
+
+func (MediaGallery) Type() ComponentType { return ComponentMediaGallery }
+
+func (MediaGallery) children() Components { return nil }
+
+func (g MediaGallery) MarshalJSON() ([]byte, error) {
+	type mediaGallery MediaGallery
+	return marshalComponent(g.Type(), mediaGallery(g))
+}
+
+func (g MediaGallery) validate(v *validator, path string) {
+	if len(g.Items) == 0 || len(g.Items) > MaxMediaGalleryItems {
+		v.addf(joinPath(path, "items"), "media galleries must contain 1 to %d items", MaxMediaGalleryItems)
+	}
+	for i, item := range g.Items {
+		itemPath := joinPath(path, fmt.Sprintf("items[%d]", i))
+		v.required(joinPath(itemPath, "media.url"), item.Media.URL)
+		v.maxLength(joinPath(itemPath, "description"), item.Description, MaxMediaDescriptionLength)
+	}
+}
+
+// FileComponent affiche un fichier joint au message, référencé par
+// "attachment://<nom>"
+type FileComponent struct {
+	ID      int           `json:"id,omitempty"`
+	File    UnfurledMedia `json:"file"`
+	Spoiler bool          `json:"spoiler,omitempty"`
+}
+
+func (FileComponent) Type() ComponentType { return ComponentFile }
+
+func (FileComponent) children() Components { return nil }
+
+func (f FileComponent) MarshalJSON() ([]byte, error) {
+	type file FileComponent
+	return marshalComponent(f.Type(), file(f))
+}
+
+func (f FileComponent) validate(v *validator, path string) {
+	if !strings.HasPrefix(f.File.URL, "attachment://") {
+		v.addf(joinPath(path, "file.url"), "file components must reference an attachment:// URL")
+	}
+}
+
+// SeparatorSpacing représente l'espacement autour d'un Separator
+type SeparatorSpacing int
+
+const (
+	SeparatorSmall SeparatorSpacing = 1
+	SeparatorLarge SeparatorSpacing = 2
+)
+
+// Separator espace verticalement les composants, avec un trait si Divider
+type Separator struct {
+	ID      int              `json:"id,omitempty"`
+	Divider bool             `json:"divider"`
+	Spacing SeparatorSpacing `json:"spacing,omitempty"`
+}
+
+func (Separator) Type() ComponentType { return ComponentSeparator }
+
+func (Separator) children() Components { return nil }
+
+func (s Separator) MarshalJSON() ([]byte, error) {
+	type separator Separator
+	return marshalComponent(s.Type(), separator(s))
+}
+
+func (s Separator) validate(v *validator, path string) {
+	if s.Spacing != 0 && s.Spacing != SeparatorSmall && s.Spacing != SeparatorLarge {
+		v.addf(joinPath(path, "spacing"), "unknown separator spacing %d", s.Spacing)
+	}
+}
+
+// Container regroupe des composants dans un cadre, avec une couleur
+// d'accentuation facultative
+type Container struct {
+	ID          int        `json:"id,omitempty"`
+	Components  Components `json:"components"`
+	AccentColor int        `json:"accent_color,omitempty"`
+	Spoiler     bool       `json:"spoiler,omitempty"`
+}
+
+func (Container) Type() ComponentType { return ComponentContainer }
+
+func (c Container) children() Components { return c.Components }
+
+func (c Container) MarshalJSON() ([]byte, error) {
+	type container Container
+	return marshalComponent(c.Type(), container(c))
+}
+
+func (c Container) validate(v *validator, path string) {
+	if len(c.Components) == 0 {
+		v.addf(joinPath(path, "components"), "containers must contain at least one component")
+	}
+	if c.AccentColor < 0 || c.AccentColor > 0xffffff {
+		v.addf(joinPath(path, "accent_color"), "color %#x is not a 24-bit RGB value", c.AccentColor)
+	}
+	validateChildren(v, joinPath(path, "components"), c.Components,
+		ComponentActionRow, ComponentTextDisplay, ComponentSection, ComponentMediaGallery, ComponentSeparator, ComponentFile)
+}
+
+// RawComponent conserve tel quel un composant d'un type inconnu de la
+// bibliothèque, tel que renvoyé par Discord
+type RawComponent struct {
+	ComponentType ComponentType
+	Data          json.RawMessage
+}
+
+func (r RawComponent) Type() ComponentType { return r.ComponentType }
+
+func (RawComponent) children() Components { return nil }
+
+func (r RawComponent) MarshalJSON() ([]byte, error) {
+	return r.Data, nil
+}
+
+func (r RawComponent) validate(v *validator, path string) {
+	v.addf(path, "unsupported %s", r.ComponentType)
+}
+
+// validateChildren vérifie que chaque composant est d'un type autorisé à cet
+// emplacement avant de le valider
+func validateChildren(v *validator, path string, components Components, allowed ...ComponentType) {
+	for i, component := range components {
+		validateChild(v, fmt.Sprintf("%s[%d]", path, i), component, allowed...)
+	}
+}
+
+func validateChild(v *validator, path string, component Component, allowed ...ComponentType) {
+	if component == nil {
+		v.addf(path, "component must not be nil")
+		return
+	}
+	if !containsType(allowed, component.Type()) {
+		v.addf(path, "%s is not allowed here", component.Type())
+		return
+	}
+	component.validate(v, path)
+}
+
+func containsType(types []ComponentType, t ComponentType) bool {
+	for _, allowed := range types {
+		if allowed == t {
+			return true
+		}
+	}
+	return false
+}
+
+// validateComponents vérifie les composants de premier niveau d'un message
+func validateComponents(v *validator, components Components, flags MessageFlags) {
+	if !flags.Has(FlagIsComponentsV2) {
+		if len(components) > MaxActionRows {
+			v.addf("components", "%d action rows exceed the limit of %d", len(components), MaxActionRows)
+		}
+		for i, component := range components {
+			if component != nil && component.Type().isLayout() {
+				v.addf(fmt.Sprintf("components[%d]", i), "%s requires the %s flag", component.Type(), FlagIsComponentsV2)
+				continue
+			}
+			validateChild(v, fmt.Sprintf("components[%d]", i), component, ComponentActionRow)
+		}
+		return
+	}
+
+	validateChildren(v, "components", components,
+		ComponentActionRow, ComponentSection, ComponentTextDisplay, ComponentMediaGallery, ComponentFile, ComponentSeparator, ComponentContainer)
+
+	count, text := 0, 0
+	walkComponents(components, func(c Component) {
+		count++
+		if t, ok := c.(interface{ text() string }); ok {
+			text += runeCount(t.text())
+		}
+	})
+	if count > MaxComponents {
+		v.addf("components", "%d components exceed the limit of %d", count, MaxComponents)
+	}
+	if text > MaxComponentsTextLength {
+		v.addf("components", "total text length %d exceeds %d characters", text, MaxComponentsTextLength)
+	}
+}
+
+// walkComponents appelle fn sur chaque composant, enfants compris
+func walkComponents(components Components, fn func(Component)) {
+	for _, component := range components {
+		if component == nil {
+			continue
+		}
+		fn(component)
+		walkComponents(component.children(), fn)
+	}
+}
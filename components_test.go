@@ -0,0 +1,196 @@
+package discordwebhook_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	discordwebhook "github.com/peepsii/discord-webhook-go"
+)
+
+func TestValidateComponents(t *testing.T) {
+	link := discordwebhook.LinkButton("Docs", "https://example.com/docs")
+	row := discordwebhook.ActionRow{Components: discordwebhook.Components{link}}
+	text := discordwebhook.TextDisplay{Content: "Deployed"}
+	texts := func(n int, content string) discordwebhook.Components {
+		components := make(discordwebhook.Components, n)
+		for i := range components {
+			components[i] = discordwebhook.TextDisplay{Content: content}
+		}
+		return components
+	}
+
+	tests := []struct {
+		name       string
+		content    string
+		components discordwebhook.Components
+		flags      discordwebhook.MessageFlags
+		want       []string
+	}{
+		{
+			name:       "link buttons",
+			components: discordwebhook.Components{row, row},
+		},
+		{
+			name:       "layout without flag",
+			components: discordwebhook.Components{text},
+			want:       []string{"components[0]"},
+		},
+		{
+			name:       "button outside an action row",
+			components: discordwebhook.Components{link},
+			want:       []string{"components[0]"},
+		},
+		{
+			name:       "nil component",
+			components: discordwebhook.Components{nil},
+			want:       []string{"components[0]"},
+		},
+		{
+			name:       "too many action rows",
+			components: discordwebhook.Components{row, row, row, row, row, row},
+			want:       []string{"components"},
+		},
+		{
+			name:       "empty action row",
+			components: discordwebhook.Components{discordwebhook.ActionRow{}},
+			want:       []string{"components[0].components"},
+		},
+		{
+			name: "button rules",
+			components: discordwebhook.Components{discordwebhook.ActionRow{Components: discordwebhook.Components{
+				discordwebhook.Button{Style: discordwebhook.ButtonPrimary, Label: "Deploy"},
+				discordwebhook.Button{Style: discordwebhook.ButtonLink, Label: "Docs"},
+				discordwebhook.Button{Style: 9, Label: "Odd"},
+				discordwebhook.Button{Style: discordwebhook.ButtonLink, URL: "https://example.com"},
+				discordwebhook.Button{Style: discordwebhook.ButtonLink, Label: strings.Repeat("l", 81), URL: "https://example.com", CustomID: "id"},
+			}}},
+			want: []string{
+				"components[0].components[0].custom_id",
+				"components[0].components[1].url",
+				"components[0].components[2].style",
+				"components[0].components[3]",
+				"components[0].components[4].label",
+				"components[0].components[4].custom_id",
+			},
+		},
+		{
+			name:  "components v2",
+			flags: discordwebhook.FlagIsComponentsV2,
+			components: discordwebhook.Components{
+				discordwebhook.Container{AccentColor: 0x3498db, Components: discordwebhook.Components{
+					text,
+					discordwebhook.Separator{Divider: true, Spacing: discordwebhook.SeparatorLarge},
+					discordwebhook.Section{
+						Components: discordwebhook.Components{text},
+						Accessory:  discordwebhook.ThumbnailComponent{Media: discordwebhook.UnfurledMedia{URL: "https://example.com/a.png"}},
+					},
+					discordwebhook.MediaGallery{Items: []discordwebhook.MediaGalleryItem{{Media: discordwebhook.UnfurledMedia{URL: "https://example.com/b.png"}}}},
+					row,
+				}},
+				discordwebhook.Section{Components: discordwebhook.Components{text}, Accessory: link},
+				discordwebhook.FileComponent{File: discordwebhook.UnfurledMedia{URL: "attachment://build.log"}},
+			},
+		},
+		{
+			name:       "components v2 with content",
+			content:    "hello",
+			flags:      discordwebhook.FlagIsComponentsV2,
+			components: discordwebhook.Components{text},
+			want:       []string{"flags"},
+		},
+		{
+			name:  "components v2 nesting",
+			flags: discordwebhook.FlagIsComponentsV2,
+			components: discordwebhook.Components{
+				discordwebhook.Container{Components: discordwebhook.Components{discordwebhook.Container{Components: discordwebhook.Components{text}}}},
+				discordwebhook.Section{Components: discordwebhook.Components{text}},
+				discordwebhook.Section{Components: texts(4, "line"), Accessory: link},
+				discordwebhook.Section{Components: discordwebhook.Components{text}, Accessory: text},
+				link,
+			},
+			want: []string{
+				"components[0].components[0]",
+				"components[1].accessory",
+				"components[2].components",
+				"components[3].accessory",
+				"components[4]",
+			},
+		},
+		{
+			name:  "components v2 fields",
+			flags: discordwebhook.FlagIsComponentsV2,
+			components: discordwebhook.Components{
+				discordwebhook.MediaGallery{},
+				discordwebhook.FileComponent{File: discordwebhook.UnfurledMedia{URL: "https://example.com/build.log"}},
+				discordwebhook.Separator{Spacing: 3},
+				discordwebhook.Container{AccentColor: 0x1000000},
+				discordwebhook.TextDisplay{Content: " "},
+				discordwebhook.RawComponent{ComponentType: 99, Data: []byte(`{"type":99}`)},
+			},
+			want: []string{
+				"components[0].items",
+				"components[1].file.url",
+				"components[2].spacing",
+				"components[3].components",
+				"components[3].accent_color",
+				"components[4].content",
+				"components[5]",
+			},
+		},
+		{
+			name:       "too many components",
+			flags:      discordwebhook.FlagIsComponentsV2,
+			components: texts(41, "line"),
+			want:       []string{"components"},
+		},
+		{
+			name:       "total text length",
+			flags:      discordwebhook.FlagIsComponentsV2,
+			components: texts(2, strings.Repeat("é", 2001)),
+			want:       []string{"components"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := discordwebhook.DiscordPayload{Content: tt.content, Components: tt.components, Flags: tt.flags}
+			if got := issuePaths(t, payload.Validate()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("issues at %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// issuePaths retourne les chemins des violations relevées par err
+func issuePaths(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var validationErr *discordwebhook.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("error = %v, want *ValidationError", err)
+	}
+	paths := make([]string, len(validationErr.Issues))
+	for i, issue := range validationErr.Issues {
+		paths[i] = issue.Path
+	}
+	return paths
+}
+
+func TestMessageBuilderValidatesComponents(t *testing.T) {
+	builder := discordwebhook.NewMessage().Components(discordwebhook.TextDisplay{Content: "Deployed"})
+	if got := issuePaths(t, builder.Err()); !reflect.DeepEqual(got, []string{"components[0]"}) {
+		t.Errorf("Err() issues at %q, want components[0]", got)
+	}
+
+	// Le drapeau posé après les composants rend le message valide
+	builder.Flags(discordwebhook.FlagIsComponentsV2)
+	if err := builder.Err(); err != nil {
+		t.Errorf("Err() = %v after setting %s", err, discordwebhook.FlagIsComponentsV2)
+	}
+	if _, err := builder.Build(); err != nil {
+		t.Errorf("Build() error = %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package discordwebhooktest
+
+import (
+	"strings"
+	"testing"
+
+	discordwebhook "github.com/peepsii/discord-webhook-go"
+)
+
+// Matcher sélectionne des appels enregistrés
+type Matcher struct {
+	// Description apparaît dans les messages d'échec des assertions
+	Description string
+	Match       func(call Call) bool
+}
+
+// Match construit un Matcher à partir d'une fonction
+func Match(description string, match func(call Call) bool) Matcher {
+	return Matcher{Description: description, Match: match}
+}
+
+// ContentContains sélectionne les payloads dont le contenu contient s
+func ContentContains(s string) Matcher {
+	return Match("content containing "+quote(s), func(call Call) bool {
+		return strings.Contains(call.Payload.Content, s)
+	})
+}
+
+// EmbedTitle sélectionne les payloads contenant un embed de titre title
+func EmbedTitle(title string) Matcher {
+	return Match("an embed titled "+quote(title), func(call Call) bool {
+		for _, embed := range call.Payload.Embeds {
+			if embed.Title == title {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// EmbedField sélectionne les payloads contenant un champ d'embed name/value
+func EmbedField(name, value string) Matcher {
+	return Match("an embed field "+quote(name)+" = "+quote(value), func(call Call) bool {
+		for _, embed := range call.Payload.Embeds {
+			for _, field := range embed.Fields {
+				if field.Name == name && field.Value == value {
+					return true
+				}
+			}
+		}
+		return false
+	})
+}
+
+// Attachment sélectionne les appels joignant un fichier nommé name
+func Attachment(name string) Matcher {
+	return Match("an attachment named "+quote(name), func(call Call) bool {
+		for _, file := range call.Files {
+			if file.Name == name {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Username sélectionne les payloads envoyés sous le nom username
+func Username(username string) Matcher {
+	return Match("username "+quote(username), func(call Call) bool {
+		return call.Payload.Username == username
+	})
+}
+
+func quote(s string) string {
+	return `"` + s + `"`
+}
+
+// Calls retourne une copie de tous les appels, réussis ou non
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// Sent retourne les envois réussis
+func (f *Fake) Sent() []Call {
+	var sent []Call
+	for _, call := range f.Calls() {
+		if call.Op == OpSend && call.Err == nil {
+			sent = append(sent, call)
+		}
+	}
+	return sent
+}
+
+// Last retourne le dernier envoi réussi
+func (f *Fake) Last() (Call, bool) {
+	sent := f.Sent()
+	if len(sent) == 0 {
+		return Call{}, false
+	}
+	return sent[len(sent)-1], true
+}
+
+// Message retourne l'état courant d'un message, nil s'il n'existe pas ou a
+// été supprimé
+func (f *Fake) Message(messageID string) *discordwebhook.Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	message, ok := f.messages[messageID]
+	if !ok {
+		return nil
+	}
+	copied := *message
+	return &copied
+}
+
+// Find retourne les envois réussis satisfaisant tous les matchers
+func (f *Fake) Find(matchers ...Matcher) []Call {
+	var found []Call
+	for _, call := range f.Sent() {
+		if matchAll(call, matchers) {
+			found = append(found, call)
+		}
+	}
+	return found
+}
+
+func matchAll(call Call, matchers []Matcher) bool {
+	for _, m := range matchers {
+		if !m.Match(call) {
+			return false
+		}
+	}
+	return true
+}
+
+// AssertSent vérifie qu'au moins un envoi réussi satisfait tous les
+// matchers, ex: fake.AssertSent(t, EmbedTitle("Deploy failed"))
+func (f *Fake) AssertSent(t testing.TB, matchers ...Matcher) {
+	t.Helper()
+	if len(f.Find(matchers...)) == 0 {
+		t.Errorf("no message sent with %s among %d sent", describe(matchers), len(f.Sent()))
+	}
+}
+
+// AssertNotSent vérifie qu'aucun envoi réussi ne satisfait tous les matchers
+func (f *Fake) AssertNotSent(t testing.TB, matchers ...Matcher) {
+	t.Helper()
+	if found := f.Find(matchers...); len(found) > 0 {
+		t.Errorf("%d message(s) sent with %s, want none", len(found), describe(matchers))
+	}
+}
+
+// AssertCount vérifie le nombre d'envois réussis
+func (f *Fake) AssertCount(t testing.TB, want int) {
+	t.Helper()
+	if got := len(f.Sent()); got != want {
+		t.Errorf("%d message(s) sent, want %d", got, want)
+	}
+}
+
+func describe(matchers []Matcher) string {
+	if len(matchers) == 0 {
+		return "any content"
+	}
+	descriptions := make([]string, len(matchers))
+	for i, m := range matchers {
+		descriptions[i] = m.Description
+	}
+	return strings.Join(descriptions, " and ")
+}
@@ -0,0 +1,386 @@
+// Package discordwebhooktest fournit des outils pour tester le code utilisant
+// discordwebhook sans contacter Discord
+package discordwebhooktest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	discordwebhook "github.com/peepsii/discord-webhook-go"
+)
+
+// Opérations enregistrées par Fake
+const (
+	OpSend   = "send"
+	OpGet    = "get"
+	OpEdit   = "edit"
+	OpDelete = "delete"
+)
+
+// FakeWebhookID est l'ID de webhook des messages créés par Fake
+const FakeWebhookID = "100000000000000000"
+
+// File est un fichier joint enregistré, son contenu étant lu à l'envoi
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Call est un appel enregistré par Fake
+type Call struct {
+	// Op vaut OpSend, OpGet, OpEdit ou OpDelete
+	Op string
+	// MessageID identifie le message créé, lu, modifié ou supprimé
+	MessageID string
+	Payload   discordwebhook.DiscordPayload
+	Files     []File
+	// Err est l'erreur retournée à l'appelant
+	Err  error
+	Time time.Time
+}
+
+// Fake est un discordwebhook.Sender en mémoire qui enregistre les appels. Il
+// valide les payloads comme Client et peut simuler des erreurs et de la
+// latence. Il peut être utilisé par plusieurs goroutines
+type Fake struct {
+	mu       sync.Mutex
+	calls    []Call
+	messages map[string]*discordwebhook.Message
+	nextID   int
+	nextFile int
+	latency  time.Duration
+	failNext []error
+	failAll  error
+	now      func() time.Time
+}
+
+var _ discordwebhook.Sender = (*Fake)(nil)
+
+// NewFake crée un faux webhook vide
+func NewFake() *Fake {
+	return &Fake{messages: make(map[string]*discordwebhook.Message), now: time.Now}
+}
+
+// SetLatency fait attendre chaque appel pendant d
+func (f *Fake) SetLatency(d time.Duration) {
+	f.mu.Lock()
+	f.latency = d
+	f.mu.Unlock()
+}
+
+// FailNext fait échouer les prochains appels avec errs, un par appel
+func (f *Fake) FailNext(errs ...error) {
+	f.mu.Lock()
+	f.failNext = append(f.failNext, errs...)
+	f.mu.Unlock()
+}
+
+// FailAlways fait échouer tous les appels avec err, nil rétablit les succès
+func (f *Fake) FailAlways(err error) {
+	f.mu.Lock()
+	f.failAll = err
+	f.mu.Unlock()
+}
+
+// Reset efface les appels, les messages et les erreurs programmées
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+	f.messages = make(map[string]*discordwebhook.Message)
+	f.failNext = nil
+	f.failAll = nil
+}
+
+// SendMessage enregistre un message simple
+func (f *Fake) SendMessage(content string) error {
+	return f.SendMessageContext(context.Background(), content)
+}
+
+// SendMessageContext est SendMessage avec un contexte
+func (f *Fake) SendMessageContext(ctx context.Context, content string) error {
+	_, err := f.send(ctx, discordwebhook.DiscordPayload{Content: content}, "")
+	return err
+}
+
+// SendEmbed enregistre un embed
+func (f *Fake) SendEmbed(embed discordwebhook.DiscordEmbed) error {
+	return f.SendEmbedContext(context.Background(), embed)
+}
+
+// SendEmbedContext est SendEmbed avec un contexte
+func (f *Fake) SendEmbedContext(ctx context.Context, embed discordwebhook.DiscordEmbed) error {
+	_, err := f.send(ctx, discordwebhook.DiscordPayload{Embeds: []discordwebhook.DiscordEmbed{embed}}, "")
+	return err
+}
+
+// SendEmbedWithFile enregistre un embed avec un fichier
+func (f *Fake) SendEmbedWithFile(embed discordwebhook.DiscordEmbed, filename string) error {
+	return f.SendEmbedWithFileContext(context.Background(), embed, filename)
+}
+
+// SendEmbedWithFileContext est SendEmbedWithFile avec un contexte
+func (f *Fake) SendEmbedWithFileContext(ctx context.Context, embed discordwebhook.DiscordEmbed, filename string) error {
+	_, err := f.send(ctx, discordwebhook.DiscordPayload{Embeds: []discordwebhook.DiscordEmbed{embed}}, filename)
+	return err
+}
+
+// SendFile enregistre un fichier
+func (f *Fake) SendFile(filename string) error {
+	return f.SendFileContext(context.Background(), filename)
+}
+
+// SendFileContext est SendFile avec un contexte
+func (f *Fake) SendFileContext(ctx context.Context, filename string) error {
+	_, err := f.send(ctx, discordwebhook.DiscordPayload{}, filename)
+	return err
+}
+
+// SendCustomPayload enregistre un payload personnalisé
+func (f *Fake) SendCustomPayload(payload discordwebhook.DiscordPayload) error {
+	return f.SendCustomPayloadContext(context.Background(), payload)
+}
+
+// SendCustomPayloadContext est SendCustomPayload avec un contexte
+func (f *Fake) SendCustomPayloadContext(ctx context.Context, payload discordwebhook.DiscordPayload) error {
+	_, err := f.send(ctx, payload, "")
+	return err
+}
+
+// SendCustomPayloadWithFile enregistre un payload personnalisé avec un fichier
+func (f *Fake) SendCustomPayloadWithFile(payload discordwebhook.DiscordPayload, filename string) error {
+	return f.SendCustomPayloadWithFileContext(context.Background(), payload, filename)
+}
+
+// SendCustomPayloadWithFileContext est SendCustomPayloadWithFile avec un
+// contexte
+func (f *Fake) SendCustomPayloadWithFileContext(ctx context.Context, payload discordwebhook.DiscordPayload, filename string) error {
+	_, err := f.send(ctx, payload, filename)
+	return err
+}
+
+// SendAndWait enregistre un payload et retourne le message créé
+func (f *Fake) SendAndWait(payload discordwebhook.DiscordPayload) (*discordwebhook.Message, error) {
+	return f.SendAndWaitContext(context.Background(), payload)
+}
+
+// SendAndWaitContext est SendAndWait avec un contexte
+func (f *Fake) SendAndWaitContext(ctx context.Context, payload discordwebhook.DiscordPayload) (*discordwebhook.Message, error) {
+	return f.send(ctx, payload, "")
+}
+
+// GetMessage retourne un message créé par le faux webhook
+func (f *Fake) GetMessage(messageID string) (*discordwebhook.Message, error) {
+	return f.GetMessageContext(context.Background(), messageID)
+}
+
+// GetMessageContext est GetMessage avec un contexte
+func (f *Fake) GetMessageContext(ctx context.Context, messageID string) (*discordwebhook.Message, error) {
+	call := Call{Op: OpGet, MessageID: messageID}
+	return f.record(ctx, &call, func() (*discordwebhook.Message, error) {
+		message, ok := f.messages[messageID]
+		if !ok {
+			return nil, unknownMessage()
+		}
+		return message, nil
+	})
+}
+
+// EditMessage modifie un message créé par le faux webhook. Comme avec
+// Discord, seuls les champs présents dans le payload sont modifiés
+func (f *Fake) EditMessage(messageID string, payload discordwebhook.DiscordPayload) (*discordwebhook.Message, error) {
+	return f.EditMessageContext(context.Background(), messageID, payload)
+}
+
+// EditMessageContext est EditMessage avec un contexte
+func (f *Fake) EditMessageContext(ctx context.Context, messageID string, payload discordwebhook.DiscordPayload) (*discordwebhook.Message, error) {
+	call := Call{Op: OpEdit, MessageID: messageID, Payload: payload}
+	return f.record(ctx, &call, func() (*discordwebhook.Message, error) {
+		existing, ok := f.messages[messageID]
+		if !ok {
+			return nil, unknownMessage()
+		}
+		now := f.now()
+		edited := *existing
+		edited.EditedTimestamp = &now
+		applyEdit(&edited, payload, f.attachments(call.Files))
+		f.messages[messageID] = &edited
+		return &edited, nil
+	})
+}
+
+// DeleteMessage supprime un message créé par le faux webhook
+func (f *Fake) DeleteMessage(messageID string) error {
+	return f.DeleteMessageContext(context.Background(), messageID)
+}
+
+// DeleteMessageContext est DeleteMessage avec un contexte
+func (f *Fake) DeleteMessageContext(ctx context.Context, messageID string) error {
+	call := Call{Op: OpDelete, MessageID: messageID}
+	_, err := f.record(ctx, &call, func() (*discordwebhook.Message, error) {
+		if _, ok := f.messages[messageID]; !ok {
+			return nil, unknownMessage()
+		}
+		delete(f.messages, messageID)
+		return nil, nil
+	})
+	return err
+}
+
+func (f *Fake) send(ctx context.Context, payload discordwebhook.DiscordPayload, filename string) (*discordwebhook.Message, error) {
+	if filename != "" {
+		payload.Files = append(payload.Files[:len(payload.Files):len(payload.Files)], discordwebhook.Attachment{Path: filename})
+	}
+
+	call := Call{Op: OpSend, Payload: payload}
+	return f.record(ctx, &call, func() (*discordwebhook.Message, error) {
+		f.nextID++
+		call.MessageID = strconv.Itoa(f.nextID)
+		message := f.message(call.MessageID, payload, call.Files)
+		f.messages[call.MessageID] = message
+		return message, nil
+	})
+}
+
+// record simule la latence et les erreurs, exécute apply sous verrou puis
+// enregistre l'appel. L'annulation de ctx interrompt la latence, l'appel étant
+// alors enregistré avec l'erreur du contexte
+func (f *Fake) record(ctx context.Context, call *Call, apply func() (*discordwebhook.Message, error)) (*discordwebhook.Message, error) {
+	f.mu.Lock()
+	latency := f.latency
+	f.mu.Unlock()
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	call.Time = f.now()
+	err := ctx.Err()
+	var message *discordwebhook.Message
+	if err == nil {
+		message, err = f.fail()
+	}
+	if err == nil && (call.Op == OpSend || call.Op == OpEdit) {
+		err = call.Payload.Validate()
+	}
+	if err == nil {
+		call.Files, err = readFiles(call.Payload.Files)
+	}
+	if err == nil {
+		message, err = apply()
+	}
+	call.Err = err
+	f.calls = append(f.calls, *call)
+
+	if message != nil {
+		copied := *message
+		message = &copied
+	}
+	return message, err
+}
+
+// fail retourne l'erreur programmée pour l'appel courant
+func (f *Fake) fail() (*discordwebhook.Message, error) {
+	if len(f.failNext) > 0 {
+		err := f.failNext[0]
+		f.failNext = f.failNext[1:]
+		return nil, err
+	}
+	return nil, f.failAll
+}
+
+// message construit le message que Discord retournerait pour payload
+func (f *Fake) message(id string, payload discordwebhook.DiscordPayload, files []File) *discordwebhook.Message {
+	message := &discordwebhook.Message{
+		ID:         id,
+		WebhookID:  FakeWebhookID,
+		Author:     &discordwebhook.MessageAuthor{ID: FakeWebhookID, Username: payload.Username, Bot: true},
+		Content:    payload.Content,
+		Timestamp:  f.now(),
+		TTS:        payload.TTS,
+		Embeds:     payload.Embeds,
+		Components: payload.Components,
+		Flags:      payload.Flags,
+		Poll:       payload.Poll,
+	}
+	message.Attachments = f.attachments(files)
+	return message
+}
+
+// attachments décrit les fichiers joints d'un message. Leurs IDs proviennent
+// d'un compteur distinct de celui des messages
+func (f *Fake) attachments(files []File) []discordwebhook.MessageAttachment {
+	var attachments []discordwebhook.MessageAttachment
+	for _, file := range files {
+		f.nextFile++
+		attachments = append(attachments, discordwebhook.MessageAttachment{
+			ID:       strconv.Itoa(f.nextFile),
+			Filename: file.Name,
+			Size:     len(file.Data),
+		})
+	}
+	return attachments
+}
+
+// applyEdit applique à message les champs présents dans le payload d'une
+// modification, comme le PATCH de Discord : les champs absents sont conservés
+// et les nouveaux fichiers s'ajoutent aux fichiers existants
+func applyEdit(message *discordwebhook.Message, payload discordwebhook.DiscordPayload, attachments []discordwebhook.MessageAttachment) {
+	if payload.Content != "" {
+		message.Content = payload.Content
+	}
+	if payload.Embeds != nil {
+		message.Embeds = payload.Embeds
+	}
+	if payload.Components != nil {
+		message.Components = payload.Components
+	}
+	if payload.Flags != 0 {
+		message.Flags = payload.Flags
+	}
+	if len(attachments) > 0 {
+		message.Attachments = append(message.Attachments[:len(message.Attachments):len(message.Attachments)], attachments...)
+	}
+}
+
+// readFiles lit le contenu des fichiers joints
+func readFiles(attachments []discordwebhook.Attachment) ([]File, error) {
+	files := make([]File, 0, len(attachments))
+	for _, attachment := range attachments {
+		file := File{Name: attachment.Name, Data: attachment.Data}
+		if file.Name == "" {
+			file.Name = filepath.Base(attachment.Path)
+		}
+		if file.Data == nil {
+			data, err := os.ReadFile(attachment.Path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open file: %w", err)
+			}
+			file.Data = data
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func unknownMessage() error {
+	return &discordwebhook.APIError{
+		StatusCode: http.StatusNotFound,
+		Status:     "404 Not Found",
+		Code:       10008,
+		Message:    "Unknown Message",
+	}
+}
@@ -0,0 +1,456 @@
+package discordwebhooktest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	discordwebhook "github.com/peepsii/discord-webhook-go"
+)
+
+// UpdateEnv est la variable d'environnement qui, à 1 ou true, réécrit les
+// fichiers golden au lieu de les comparer, ex:
+// DISCORDWEBHOOKTEST_UPDATE=1 go test ./...
+const UpdateEnv = "DISCORDWEBHOOKTEST_UPDATE"
+
+// GoldenEntry est un échange enregistré dans un fichier golden
+type GoldenEntry struct {
+	Request  GoldenRequest  `json:"request"`
+	Response GoldenResponse `json:"response"`
+}
+
+// GoldenRequest est une requête normalisée : le jeton est masqué, le payload
+// indenté et les fichiers joints réduits à leur empreinte
+type GoldenRequest struct {
+	Method      string             `json:"method"`
+	Path        string             `json:"path"`
+	Query       string             `json:"query,omitempty"`
+	Payload     json.RawMessage    `json:"payload,omitempty"`
+	Attachments []GoldenAttachment `json:"attachments,omitempty"`
+}
+
+// GoldenAttachment décrit un fichier joint par son nom, sa taille et son
+// empreinte SHA-256
+type GoldenAttachment struct {
+	Name   string `json:"name"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// GoldenResponse est la réponse rejouée pour une requête
+type GoldenResponse struct {
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// Golden est un http.RoundTripper qui enregistre les requêtes du client et
+// les compare au fichier golden Path. Aucune requête n'atteint Discord : les
+// réponses enregistrées sont rejouées, ou une réponse réussie est simulée. En
+// mode mise à jour, les requêtes sont transmises à Next s'il est défini et le
+// fichier est réécrit par Verify
+type Golden struct {
+	// Path est le chemin du fichier golden, ex: testdata/alert.golden
+	Path string
+	// Update réécrit le fichier golden, par défaut selon la variable
+	// UpdateEnv. Un flag propre au test peut aussi le définir
+	Update bool
+	// Next reçoit les requêtes en mode mise à jour, nil pour simuler Discord
+	Next http.RoundTripper
+	// IgnoreFields remplace la valeur de ces champs JSON du payload, à toute
+	// profondeur, ex: "timestamp" pour un horodatage variable
+	IgnoreFields []string
+
+	mu       sync.Mutex
+	entries  []GoldenEntry
+	recorded []GoldenEntry
+	loaded   bool
+}
+
+// NewGolden crée un transport golden pour le fichier path
+func NewGolden(path string) *Golden {
+	env := strings.ToLower(os.Getenv(UpdateEnv))
+	return &Golden{Path: path, Update: env == "1" || env == "true"}
+}
+
+// GoldenOption configure un client avec un transport golden pour path et
+// vérifie le fichier à la fin du test, ex:
+//
+//	client := discordwebhook.NewClient(webhookURL, discordwebhooktest.GoldenOption(t, "testdata/alert.golden"))
+func GoldenOption(t testing.TB, path string, ignoreFields ...string) discordwebhook.Option {
+	g := NewGolden(path)
+	g.IgnoreFields = ignoreFields
+	t.Cleanup(func() { g.Verify(t) })
+	return discordwebhook.WithTransport(g)
+}
+
+// ignoredValue remplace les champs de Golden.IgnoreFields
+const ignoredValue = "<ignored>"
+
+// RoundTrip enregistre la requête et retourne la réponse rejouée
+func (g *Golden) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = data
+	}
+
+	recorded, err := g.normalize(req, body)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	index := len(g.entries)
+	g.entries = append(g.entries, GoldenEntry{Request: recorded})
+	update, next := g.Update, g.Next
+	var replay *GoldenResponse
+	if !update {
+		g.load()
+		if index < len(g.recorded) {
+			replay = &g.recorded[index].Response
+		}
+	}
+	g.mu.Unlock()
+
+	var response GoldenResponse
+	switch {
+	case replay != nil:
+		response = *replay
+	case update && next != nil:
+		forwarded := req.Clone(req.Context())
+		forwarded.Body = io.NopCloser(bytes.NewReader(body))
+		resp, err := next.RoundTrip(forwarded)
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		response = GoldenResponse{Status: resp.StatusCode}
+		if json.Valid(data) {
+			response.Body = data
+		}
+	default:
+		response = simulate(req, body)
+	}
+
+	g.mu.Lock()
+	g.entries[index].Response = response
+	g.mu.Unlock()
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", response.Status, http.StatusText(response.Status)),
+		StatusCode:    response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(response.Body)),
+		ContentLength: int64(len(response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Entries retourne une copie des échanges enregistrés
+func (g *Golden) Entries() []GoldenEntry {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]GoldenEntry(nil), g.entries...)
+}
+
+// Verify réécrit le fichier golden en mode mise à jour, sinon signale à t les
+// différences avec les requêtes enregistrées sous forme de diff
+func (g *Golden) Verify(t testing.TB) {
+	t.Helper()
+	got, err := g.marshal()
+	if err != nil {
+		t.Fatalf("golden %s: %v", g.Path, err)
+		return
+	}
+
+	if g.Update {
+		if err := os.MkdirAll(filepath.Dir(g.Path), 0o755); err != nil {
+			t.Fatalf("golden %s: %v", g.Path, err)
+		}
+		if err := os.WriteFile(g.Path, got, 0o644); err != nil {
+			t.Fatalf("golden %s: %v", g.Path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(g.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Errorf("golden %s does not exist, run the test with %s=1 to create it", g.Path, UpdateEnv)
+		return
+	}
+	if err != nil {
+		t.Fatalf("golden %s: %v", g.Path, err)
+		return
+	}
+	if !bytes.Equal(want, got) {
+		t.Errorf("golden %s mismatch (-want +got):\n%s", g.Path, Diff(string(want), string(got)))
+	}
+}
+
+// marshal encode les requêtes enregistrées ; les réponses ne sont
+// enregistrées qu'en mode mise à jour, sinon celles du fichier sont reprises
+func (g *Golden) marshal() ([]byte, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	entries := append([]GoldenEntry(nil), g.entries...)
+	if !g.Update {
+		g.load()
+		for i := range entries {
+			entries[i].Response = GoldenResponse{}
+			if i < len(g.recorded) {
+				entries[i].Response = g.recorded[i].Response
+			}
+		}
+	}
+	if entries == nil {
+		entries = []GoldenEntry{}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(entries); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// load lit une fois le fichier golden, g.mu doit être verrouillé
+func (g *Golden) load() {
+	if g.loaded {
+		return
+	}
+	g.loaded = true
+	if data, err := os.ReadFile(g.Path); err == nil {
+		json.Unmarshal(data, &g.recorded)
+	}
+}
+
+var (
+	webhookPath = regexp.MustCompile(`(/webhooks/[^/]+/)[^/?]+`)
+	messagePath = regexp.MustCompile(`/messages/(\d+)`)
+)
+
+// goldenTime horodate les messages simulés afin que les réponses
+// enregistrées restent stables
+var goldenTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// normalize construit la forme enregistrée d'une requête
+func (g *Golden) normalize(req *http.Request, body []byte) (GoldenRequest, error) {
+	recorded := GoldenRequest{
+		Method: req.Method,
+		Path:   webhookPath.ReplaceAllString(req.URL.Path, "${1}REDACTED"),
+		Query:  req.URL.Query().Encode(),
+	}
+	if len(body) == 0 {
+		return recorded, nil
+	}
+
+	payload, attachments, err := splitBody(req.Header.Get("Content-Type"), body)
+	if err != nil {
+		return recorded, err
+	}
+	recorded.Attachments = attachments
+
+	var decoded any
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		return recorded, fmt.Errorf("invalid payload JSON: %w", err)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(ignore(decoded, g.IgnoreFields)); err != nil {
+		return recorded, err
+	}
+	recorded.Payload = bytes.TrimSpace(buf.Bytes())
+	return recorded, nil
+}
+
+// splitBody sépare le payload JSON et les fichiers joints d'un corps JSON ou
+// multipart/form-data
+func splitBody(contentType string, body []byte) ([]byte, []GoldenAttachment, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid content type: %w", err)
+	}
+	if mediaType != "multipart/form-data" {
+		return body, nil, nil
+	}
+
+	var payload []byte
+	var attachments []GoldenAttachment
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid multipart body: %w", err)
+		}
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid multipart body: %w", err)
+		}
+
+		if part.FormName() == "payload_json" {
+			payload = data
+			continue
+		}
+		sum := sha256.Sum256(data)
+		attachments = append(attachments, GoldenAttachment{
+			Name:   part.FileName(),
+			Size:   len(data),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+	}
+	return payload, attachments, nil
+}
+
+// ignore remplace récursivement la valeur des champs fields
+func ignore(v any, fields []string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if contains(fields, key) {
+				v[key] = ignoredValue
+			} else {
+				v[key] = ignore(value, fields)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = ignore(value, fields)
+		}
+	}
+	return v
+}
+
+func contains(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
+
+// simulate retourne la réponse de Discord à une requête réussie
+func simulate(req *http.Request, body []byte) GoldenResponse {
+	switch {
+	case req.Method == http.MethodDelete:
+		return GoldenResponse{Status: http.StatusNoContent}
+	case req.Method == http.MethodPost && req.URL.Query().Get("wait") != "true":
+		return GoldenResponse{Status: http.StatusNoContent}
+	}
+
+	var payload discordwebhook.DiscordPayload
+	var files []File
+	if len(body) > 0 {
+		payload, files, _ = DecodeRequest(req.Header.Get("Content-Type"), body)
+	}
+	id := "500000000000000001"
+	if match := messagePath.FindStringSubmatch(req.URL.Path); match != nil {
+		id = match[1]
+	}
+	channelID := channel(req.URL.Query().Get("thread_id"))
+	message := newMessage(id, channelID, payload, fileAttachments(channelID, files, 1))
+	message.Timestamp = goldenTime
+	if message.EditedTimestamp != nil {
+		message.EditedTimestamp = &goldenTime
+	}
+	data, _ := json.Marshal(message)
+	return GoldenResponse{Status: http.StatusOK, Body: data}
+}
+
+// Diff retourne les lignes différentes entre want et got, préfixées par "-"
+// et "+", avec trois lignes de contexte
+func Diff(want, got string) string {
+	a, b := strings.Split(want, "\n"), strings.Split(got, "\n")
+
+	// Plus longue sous-séquence commune des lignes
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+	}
+	var lines []line
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, line{' ', a[i]})
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, line{'+', b[j]})
+			j++
+		}
+	}
+
+	const context = 3
+	var out strings.Builder
+	skipped := false
+	for k, l := range lines {
+		near := false
+		for d := max(0, k-context); d <= min(len(lines)-1, k+context); d++ {
+			if lines[d].op != ' ' {
+				near = true
+				break
+			}
+		}
+		if !near {
+			skipped = true
+			continue
+		}
+		if skipped {
+			out.WriteString("...\n")
+			skipped = false
+		}
+		out.WriteByte(l.op)
+		out.WriteString(" " + l.text + "\n")
+	}
+	return out.String()
+}
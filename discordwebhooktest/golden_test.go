@@ -0,0 +1,244 @@
+package discordwebhooktest_test
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	discordwebhook "github.com/peepsii/discord-webhook-go"
+	"github.com/peepsii/discord-webhook-go/discordwebhooktest"
+)
+
+const goldenWebhookURL = "https://discord.com/api/webhooks/123/secret-token"
+
+// recorder capture les échecs signalés par Golden.Verify
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+}
+
+// sendAlert envoie le même message à chaque exécution, hormis l'horodatage
+func sendAlert(t *testing.T, g *discordwebhooktest.Golden, content string) *discordwebhook.Message {
+	t.Helper()
+	client := discordwebhook.NewClient(goldenWebhookURL, discordwebhook.WithTransport(g))
+	message, err := client.SendAndWait(discordwebhook.DiscordPayload{
+		Content: content,
+		Embeds:  []discordwebhook.DiscordEmbed{{Title: "CPU", Timestamp: time.Now()}},
+		Files:   []discordwebhook.Attachment{{Name: "top.txt", Data: []byte("load 12.5")}},
+	})
+	if err != nil {
+		t.Fatalf("SendAndWait() error = %v", err)
+	}
+	if err := client.DeleteMessage(message.ID); err != nil {
+		t.Fatalf("DeleteMessage() error = %v", err)
+	}
+	return message
+}
+
+func newGolden(path string, update bool) *discordwebhooktest.Golden {
+	g := discordwebhooktest.NewGolden(path)
+	g.Update = update
+	g.IgnoreFields = []string{"timestamp"}
+	return g
+}
+
+func TestGoldenRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "alert.golden")
+
+	recording := newGolden(path, true)
+	recorded := sendAlert(t, recording, "High CPU")
+	r := &recorder{TB: t}
+	recording.Verify(r)
+	if len(r.errors) > 0 {
+		t.Fatalf("Verify() in update mode: %v", r.errors)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("golden file not written: %v", err)
+	}
+	for _, want := range []string{`"path": "/api/webhooks/123/REDACTED"`, `"query": "wait=true"`, `"name": "top.txt"`, `<ignored>`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("golden file lacks %s:\n%s", want, data)
+		}
+	}
+	if strings.Contains(string(data), "secret-token") {
+		t.Errorf("golden file leaks the token:\n%s", data)
+	}
+
+	replaying := newGolden(path, false)
+	replayed := sendAlert(t, replaying, "High CPU")
+	r = &recorder{TB: t}
+	replaying.Verify(r)
+	if len(r.errors) > 0 {
+		t.Errorf("Verify() in replay mode: %v", r.errors)
+	}
+	if replayed.ID != recorded.ID || !replayed.Timestamp.Equal(recorded.Timestamp) {
+		t.Errorf("replayed message %s at %s, want %s at %s", replayed.ID, replayed.Timestamp, recorded.ID, recorded.Timestamp)
+	}
+	if n := len(replaying.Entries()); n != 2 {
+		t.Errorf("got %d entries, want 2", n)
+	}
+}
+
+func TestGoldenMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alert.golden")
+	recording := newGolden(path, true)
+	sendAlert(t, recording, "High CPU")
+	recording.Verify(t)
+
+	replaying := newGolden(path, false)
+	sendAlert(t, replaying, "Low disk")
+	r := &recorder{TB: t}
+	replaying.Verify(r)
+	if len(r.errors) != 1 {
+		t.Fatalf("Verify() reported %d errors, want 1", len(r.errors))
+	}
+	if !strings.Contains(r.errors[0], "mismatch") {
+		t.Errorf("Verify() error = %s, want a mismatch", r.errors[0])
+	}
+	for _, want := range []string{`- "content": "High CPU",`, `+ "content": "Low disk",`} {
+		if !containsLine(r.errors[0], want) {
+			t.Errorf("Verify() diff lacks %q:\n%s", want, r.errors[0])
+		}
+	}
+}
+
+// containsLine indique si une ligne de s, indentation du diff ignorée, vaut
+// line
+func containsLine(s, line string) bool {
+	for _, l := range strings.Split(s, "\n") {
+		if len(l) > 2 && l[:2] == line[:2] && strings.TrimSpace(l[2:]) == line[2:] {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGoldenMissingFile(t *testing.T) {
+	g := newGolden(filepath.Join(t.TempDir(), "missing.golden"), false)
+	sendAlert(t, g, "High CPU")
+	r := &recorder{TB: t}
+	g.Verify(r)
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], discordwebhooktest.UpdateEnv+"=1") {
+		t.Errorf("Verify() errors = %v, want a hint to set %s", r.errors, discordwebhooktest.UpdateEnv)
+	}
+}
+
+func TestGoldenForwardsToNextWhenUpdating(t *testing.T) {
+	server := discordwebhooktest.NewServer()
+	t.Cleanup(server.Close)
+	path := filepath.Join(t.TempDir(), "get.golden")
+
+	for _, update := range []bool{true, false} {
+		g := newGolden(path, update)
+		g.Next = http.DefaultTransport
+		client := discordwebhook.NewClient(server.WebhookURL(), discordwebhook.WithBaseURL(server.URL), discordwebhook.WithTransport(g))
+		webhook, err := client.GetWebhook()
+		if err != nil {
+			t.Fatalf("GetWebhook() with update = %v error = %v", update, err)
+		}
+		if webhook.Name != discordwebhooktest.ServerWebhookName {
+			t.Errorf("GetWebhook() with update = %v = %+v", update, webhook)
+		}
+		g.Verify(t)
+	}
+	if n := len(server.Requests()); n != 1 {
+		t.Errorf("server got %d requests, want only the recording one", n)
+	}
+}
+
+func TestGoldenUpdateEnv(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"", false},
+		{"0", false},
+		{"false", false},
+		{"1", true},
+		{"true", true},
+		{"TRUE", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv(discordwebhooktest.UpdateEnv, tt.value)
+			if got := discordwebhooktest.NewGolden("x.golden").Update; got != tt.want {
+				t.Errorf("Update = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	numbers := func(from, to int, replace map[int]string) string {
+		var lines []string
+		for i := from; i <= to; i++ {
+			line := fmt.Sprint(i)
+			if s, ok := replace[i]; ok {
+				line = s
+			}
+			lines = append(lines, line)
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	tests := []struct {
+		name string
+		want string
+		got  string
+		diff string
+	}{
+		{
+			name: "equal",
+			want: "a\nb",
+			got:  "a\nb",
+			diff: "",
+		},
+		{
+			name: "changed line",
+			want: "a\nb\nc",
+			got:  "a\nx\nc",
+			diff: "  a\n- b\n+ x\n  c\n",
+		},
+		{
+			name: "added and removed lines",
+			want: "a\nb",
+			got:  "b\nc",
+			diff: "- a\n  b\n+ c\n",
+		},
+		{
+			name: "context",
+			want: numbers(1, 20, nil),
+			got:  numbers(1, 20, map[int]string{10: "ten"}),
+			diff: "...\n  7\n  8\n  9\n- 10\n+ ten\n  11\n  12\n  13\n",
+		},
+		{
+			name: "separate hunks",
+			want: numbers(1, 12, nil),
+			got:  numbers(1, 12, map[int]string{1: "one", 12: "twelve"}),
+			diff: "- 1\n+ one\n  2\n  3\n  4\n...\n  9\n  10\n  11\n- 12\n+ twelve\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := discordwebhooktest.Diff(tt.want, tt.got); got != tt.diff {
+				t.Errorf("Diff() =\n%s\nwant\n%s", got, tt.diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,494 @@
+package discordwebhooktest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	discordwebhook "github.com/peepsii/discord-webhook-go"
+)
+
+// Identifiants du webhook émulé par Server
+const (
+	ServerWebhookID    = "200000000000000000"
+	ServerWebhookToken = "fake-token"
+	ServerChannelID    = "300000000000000000"
+	ServerGuildID      = "400000000000000000"
+	ServerWebhookName  = "Fake Webhook"
+)
+
+// serverBucket est le bucket de limite de débit annoncé par Server
+const serverBucket = "fake-webhook-bucket"
+
+// Response est une réponse programmée de Server
+type Response struct {
+	Status int
+	Header http.Header
+	Body   string
+}
+
+// RateLimited retourne une réponse 429 avec les en-têtes et le corps envoyés
+// par Discord
+func RateLimited(retryAfter time.Duration, global bool) Response {
+	seconds := strconv.FormatFloat(retryAfter.Seconds(), 'f', 3, 64)
+	scope := "user"
+	if global {
+		scope = "global"
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds()+0.999)))
+	header.Set("X-RateLimit-Limit", "5")
+	header.Set("X-RateLimit-Remaining", "0")
+	header.Set("X-RateLimit-Reset-After", seconds)
+	header.Set("X-RateLimit-Bucket", serverBucket)
+	header.Set("X-RateLimit-Scope", scope)
+	if global {
+		header.Set("X-RateLimit-Global", "true")
+	}
+
+	body, _ := json.Marshal(map[string]any{
+		"message":     "You are being rate limited.",
+		"retry_after": retryAfter.Seconds(),
+		"global":      global,
+	})
+	return Response{Status: http.StatusTooManyRequests, Header: header, Body: string(body)}
+}
+
+// ServerError retourne une réponse d'erreur 5xx, ex: http.StatusBadGateway
+func ServerError(status int) Response {
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	body, _ := json.Marshal(map[string]any{"message": http.StatusText(status), "code": 0})
+	return Response{Status: status, Header: header, Body: string(body)}
+}
+
+// ServerRequest est une requête reçue par Server
+type ServerRequest struct {
+	Method    string
+	MessageID string
+	ThreadID  string
+	Wait      bool
+	Header    http.Header
+	// Payload et Files sont décodés du corps JSON ou multipart
+	Payload discordwebhook.DiscordPayload
+	Files   []File
+}
+
+// Server émule les points d'accès d'un webhook Discord avec httptest : il
+// valide les payloads comme Discord, crée, modifie, lit et supprime des
+// messages, retourne les informations du webhook, gère les threads et rejoue
+// des réponses programmées, ex: 429
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []ServerRequest
+	messages map[string]*discordwebhook.Message
+	script   []Response
+	nextID   int
+	nextFile int
+}
+
+// NewServer démarre un serveur, à fermer avec Close
+func NewServer() *Server {
+	s := &Server{messages: make(map[string]*discordwebhook.Message)}
+
+	mux := http.NewServeMux()
+	for _, prefix := range []string{"/api", "/api/{version}"} {
+		webhook := prefix + "/webhooks/{id}/{token}"
+		mux.HandleFunc("GET "+webhook, s.handleWebhook)
+		mux.HandleFunc("POST "+webhook, s.handleExecute)
+		mux.HandleFunc("GET "+webhook+"/messages/{message}", s.handleGet)
+		mux.HandleFunc("PATCH "+webhook+"/messages/{message}", s.handleEdit)
+		mux.HandleFunc("DELETE "+webhook+"/messages/{message}", s.handleDelete)
+	}
+	s.Server = httptest.NewServer(s.middleware(mux))
+	return s
+}
+
+// WebhookURL retourne l'URL du webhook émulé, à utiliser avec
+// discordwebhook.WithBaseURL(s.URL)
+func (s *Server) WebhookURL() string {
+	return "https://discord.com/api/webhooks/" + ServerWebhookID + "/" + ServerWebhookToken
+}
+
+// Client retourne un client envoyant ses requêtes au serveur
+func (s *Server) Client(options ...discordwebhook.Option) *discordwebhook.Client {
+	options = append(options[:len(options):len(options)], discordwebhook.WithBaseURL(s.URL))
+	return discordwebhook.NewClient(s.WebhookURL(), options...)
+}
+
+// Enqueue programme les prochaines réponses du serveur, une par requête,
+// avant tout traitement de la requête
+func (s *Server) Enqueue(responses ...Response) {
+	s.mu.Lock()
+	s.script = append(s.script, responses...)
+	s.mu.Unlock()
+}
+
+// Requests retourne une copie des requêtes reçues
+func (s *Server) Requests() []ServerRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ServerRequest(nil), s.requests...)
+}
+
+// Message retourne l'état courant d'un message, nil s'il n'existe pas
+func (s *Server) Message(messageID string) *discordwebhook.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	message, ok := s.messages[messageID]
+	if !ok {
+		return nil
+	}
+	copied := *message
+	return &copied
+}
+
+// middleware décode et enregistre la requête, puis rejoue la prochaine
+// réponse programmée ou transmet la requête au routeur
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := ServerRequest{
+			Method:   r.Method,
+			ThreadID: r.URL.Query().Get("thread_id"),
+			Wait:     r.URL.Query().Get("wait") == "true",
+			Header:   r.Header.Clone(),
+		}
+		if _, rest, ok := strings.Cut(r.URL.Path, "/messages/"); ok {
+			req.MessageID = rest
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, 50109, "The request body contains invalid JSON.", nil)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if len(body) > 0 {
+			req.Payload, req.Files, err = DecodeRequest(r.Header.Get("Content-Type"), body)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, 50109, "The request body contains invalid JSON.", nil)
+				return
+			}
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, req)
+		var scripted *Response
+		if len(s.script) > 0 {
+			scripted = &s.script[0]
+			s.script = s.script[1:]
+		}
+		s.mu.Unlock()
+
+		if scripted != nil {
+			for key, values := range scripted.Header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(scripted.Status)
+			io.WriteString(w, scripted.Body)
+			return
+		}
+
+		setRateLimitHeaders(w.Header())
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestKey{}, req)))
+	})
+}
+
+// authorize vérifie l'ID et le jeton du webhook
+func authorize(w http.ResponseWriter, r *http.Request) bool {
+	if r.PathValue("id") != ServerWebhookID {
+		writeError(w, http.StatusNotFound, 10015, "Unknown Webhook", nil)
+		return false
+	}
+	if r.PathValue("token") != ServerWebhookToken {
+		writeError(w, http.StatusUnauthorized, 50027, "Invalid Webhook Token", nil)
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":         ServerWebhookID,
+		"type":       1,
+		"guild_id":   ServerGuildID,
+		"channel_id": ServerChannelID,
+		"name":       ServerWebhookName,
+		"avatar":     nil,
+		"token":      ServerWebhookToken,
+	})
+}
+
+func (s *Server) handleExecute(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r) {
+		return
+	}
+	req := request(r)
+	if !validate(w, req.Payload, req.Files) {
+		return
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	id := strconv.Itoa(500000000000000000 + s.nextID)
+	channelID := channel(req.ThreadID)
+	message := newMessage(id, channelID, req.Payload, s.attachments(channelID, req.Files))
+	s.messages[id] = message
+	copied := *message
+	s.mu.Unlock()
+
+	if !req.Wait {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, &copied)
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r) {
+		return
+	}
+	s.mu.Lock()
+	message, ok := s.findMessage(r)
+	var copied discordwebhook.Message
+	if ok {
+		copied = *message
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, 10008, "Unknown Message", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, &copied)
+}
+
+func (s *Server) handleEdit(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r) {
+		return
+	}
+	req := request(r)
+	if !validate(w, req.Payload, req.Files) {
+		return
+	}
+
+	s.mu.Lock()
+	existing, ok := s.findMessage(r)
+	var copied discordwebhook.Message
+	if ok {
+		now := time.Now().UTC()
+		edited := *existing
+		edited.EditedTimestamp = &now
+		applyEdit(&edited, req.Payload, s.attachments(existing.ChannelID, req.Files))
+		s.messages[existing.ID] = &edited
+		copied = edited
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, 10008, "Unknown Message", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, &copied)
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r) {
+		return
+	}
+	s.mu.Lock()
+	message, ok := s.findMessage(r)
+	if ok {
+		delete(s.messages, message.ID)
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, 10008, "Unknown Message", nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findMessage retourne le message de la requête s'il appartient au salon ou
+// au thread demandé, s.mu doit être verrouillé
+func (s *Server) findMessage(r *http.Request) (*discordwebhook.Message, bool) {
+	message, ok := s.messages[r.PathValue("message")]
+	if !ok || message.ChannelID != channel(r.URL.Query().Get("thread_id")) {
+		return nil, false
+	}
+	return message, true
+}
+
+type requestKey struct{}
+
+// request retourne la requête décodée par middleware
+func request(r *http.Request) ServerRequest {
+	req, _ := r.Context().Value(requestKey{}).(ServerRequest)
+	return req
+}
+
+// channel retourne le salon d'un message, le thread lorsqu'il est précisé
+func channel(threadID string) string {
+	if threadID != "" {
+		return threadID
+	}
+	return ServerChannelID
+}
+
+// validate répond 400 lorsque le payload serait rejeté par Discord
+func validate(w http.ResponseWriter, payload discordwebhook.DiscordPayload, files []File) bool {
+	payload.Files = make([]discordwebhook.Attachment, len(files))
+	for i, file := range files {
+		payload.Files[i] = discordwebhook.Attachment{Name: file.Name, Data: file.Data}
+	}
+
+	var validationErr *discordwebhook.ValidationError
+	if err := payload.Validate(); errors.As(err, &validationErr) {
+		fields := make(map[string]any, len(validationErr.Issues))
+		for _, issue := range validationErr.Issues {
+			fields[issue.Path] = map[string]any{
+				"_errors": []map[string]string{{"code": "BASE_TYPE_INVALID", "message": issue.Message}},
+			}
+		}
+		writeError(w, http.StatusBadRequest, 50035, "Invalid Form Body", fields)
+		return false
+	}
+	return true
+}
+
+// newMessage construit le message que Discord retournerait pour payload
+func newMessage(id, channelID string, payload discordwebhook.DiscordPayload, attachments []discordwebhook.MessageAttachment) *discordwebhook.Message {
+	username := payload.Username
+	if username == "" {
+		username = ServerWebhookName
+	}
+	message := &discordwebhook.Message{
+		ID:         id,
+		ChannelID:  channelID,
+		WebhookID:  ServerWebhookID,
+		Author:     &discordwebhook.MessageAuthor{ID: ServerWebhookID, Username: username, Avatar: payload.Avatar, Bot: true},
+		Content:    payload.Content,
+		Timestamp:  time.Now().UTC(),
+		TTS:        payload.TTS,
+		Embeds:     payload.Embeds,
+		Components: payload.Components,
+		Flags:      payload.Flags,
+		Poll:       payload.Poll,
+	}
+	if message.Embeds == nil {
+		message.Embeds = []discordwebhook.DiscordEmbed{}
+	}
+	message.Attachments = attachments
+	return message
+}
+
+// attachments décrit les fichiers joints d'un message. Leurs IDs proviennent
+// d'un compteur distinct de celui des messages, s.mu devant être verrouillé
+func (s *Server) attachments(channelID string, files []File) []discordwebhook.MessageAttachment {
+	attachments := fileAttachments(channelID, files, s.nextFile+1)
+	s.nextFile += len(files)
+	return attachments
+}
+
+// fileAttachments décrit les fichiers joints d'un message, numérotés à partir
+// de first
+func fileAttachments(channelID string, files []File, first int) []discordwebhook.MessageAttachment {
+	attachments := []discordwebhook.MessageAttachment{}
+	for i, file := range files {
+		attachmentID := strconv.Itoa(600000000000000000 + first + i)
+		url := fmt.Sprintf("https://cdn.discordapp.com/attachments/%s/%s/%s", channelID, attachmentID, file.Name)
+		attachments = append(attachments, discordwebhook.MessageAttachment{
+			ID:          attachmentID,
+			Filename:    file.Name,
+			Size:        len(file.Data),
+			URL:         url,
+			ProxyURL:    strings.Replace(url, "cdn.discordapp.com", "media.discordapp.net", 1),
+			ContentType: http.DetectContentType(file.Data),
+		})
+	}
+	return attachments
+}
+
+// DecodeRequest décode le corps JSON ou multipart/form-data d'une requête
+// d'envoi en payload et fichiers joints
+func DecodeRequest(contentType string, body []byte) (discordwebhook.DiscordPayload, []File, error) {
+	var payload discordwebhook.DiscordPayload
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return payload, nil, fmt.Errorf("invalid content type: %w", err)
+	}
+
+	if mediaType != "multipart/form-data" {
+		err := json.Unmarshal(body, &payload)
+		return payload, nil, err
+	}
+
+	var files []File
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return payload, nil, fmt.Errorf("invalid multipart body: %w", err)
+		}
+
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return payload, nil, fmt.Errorf("invalid multipart body: %w", err)
+		}
+		switch name := part.FormName(); {
+		case name == "payload_json":
+			if err := json.Unmarshal(data, &payload); err != nil {
+				return payload, nil, err
+			}
+		case strings.HasPrefix(name, "files["):
+			files = append(files, File{Name: part.FileName(), Data: data})
+		}
+	}
+	return payload, files, nil
+}
+
+// setRateLimitHeaders ajoute les en-têtes de limite de débit d'une réponse
+// réussie
+func setRateLimitHeaders(header http.Header) {
+	header.Set("X-RateLimit-Limit", "5")
+	header.Set("X-RateLimit-Remaining", "4")
+	header.Set("X-RateLimit-Reset-After", "2.000")
+	header.Set("X-RateLimit-Bucket", serverBucket)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError répond avec une erreur JSON au format de Discord
+func writeError(w http.ResponseWriter, status, code int, message string, fields map[string]any) {
+	body := map[string]any{"code": code, "message": message}
+	if fields != nil {
+		body["errors"] = fields
+	}
+	writeJSON(w, status, body)
+}
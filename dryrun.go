@@ -0,0 +1,181 @@
+package discordwebhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrDryRun est retournée par les lectures, sans objet en mode dry-run
+var ErrDryRun = errors.New("not available in dry-run mode")
+
+// DryRun est une requête que le client aurait envoyée à Discord
+type DryRun struct {
+	Method string
+	// URL est l'URL ciblée, le jeton du webhook étant masqué
+	URL     string
+	Payload DiscordPayload
+	// Files contient les fichiers joints, lus depuis le disque si besoin
+	Files []DryRunFile
+}
+
+// DryRunFile est un fichier joint à une requête dry-run
+type DryRunFile struct {
+	Name string
+	Data []byte
+}
+
+// WithDryRunFunc remplace l'envoi des requêtes par un appel à fn. Les options
+// du client, le découpage AutoFit, les middlewares et la validation
+// s'appliquent comme pour un envoi réel, seule la requête HTTP est omise.
+// SendAndWait et EditMessage retournent un message construit à partir du
+// payload, GetMessage et GetWebhook retournent ErrDryRun
+func WithDryRunFunc(fn func(run DryRun) error) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.dryRun = fn
+	})
+}
+
+// WithDryRun écrit les requêtes dans w au lieu de les envoyer, ex: os.Stdout,
+// voir WithDryRunFunc
+func WithDryRun(w io.Writer) Option {
+	var mu sync.Mutex
+	return WithDryRunFunc(func(run DryRun) error {
+		data, err := json.MarshalIndent(run.Payload, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal payload: %w", err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(w, "%s %s\n", run.Method, run.URL)
+		if run.Method != http.MethodDelete {
+			fmt.Fprintf(w, "%s\n", data)
+		}
+		for _, file := range run.Files {
+			fmt.Fprintf(w, "attachment: %s (%d bytes)\n", file.Name, len(file.Data))
+		}
+		_, err = fmt.Fprintln(w)
+		return err
+	})
+}
+
+// WithDryRunDir écrit chaque requête dans dir au lieu de l'envoyer : le
+// payload dans NNNN-payload.json et les fichiers joints dans NNNN-<nom>, voir
+// WithDryRunFunc
+func WithDryRunDir(dir string) Option {
+	var mu sync.Mutex
+	n := 0
+	return WithDryRunFunc(func(run DryRun) error {
+		mu.Lock()
+		n++
+		prefix := filepath.Join(dir, fmt.Sprintf("%04d-", n))
+		mu.Unlock()
+
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create dry-run directory: %w", err)
+		}
+		data, err := json.MarshalIndent(struct {
+			Method  string         `json:"method"`
+			URL     string         `json:"url"`
+			Payload DiscordPayload `json:"payload"`
+		}{run.Method, run.URL, run.Payload}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		if err := os.WriteFile(prefix+"payload.json", append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("failed to write payload: %w", err)
+		}
+		for _, file := range run.Files {
+			if err := os.WriteFile(prefix+filepath.Base(file.Name), file.Data, 0o644); err != nil {
+				return fmt.Errorf("failed to write attachment: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// dryRunHandler remplace handle lorsque le mode dry-run est actif
+func (c *Client) dryRunHandler(fn func(run DryRun) error) Handler {
+	var mu sync.Mutex
+	n := 0
+	return HandlerFunc(func(ctx context.Context, req *Request) (*Message, error) {
+		if req.Method == http.MethodGet {
+			return nil, ErrDryRun
+		}
+
+		endpoint, err := c.requestEndpoint(req)
+		if err != nil {
+			return nil, err
+		}
+		run := DryRun{Method: req.Method, URL: c.WebhookURL.redact(endpoint)}
+		if req.Method != http.MethodDelete {
+			if err := req.Payload.Validate(); err != nil {
+				return nil, err
+			}
+			run.Payload = req.Payload
+			if run.Files, err = readAttachments(req.Payload.Files); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := fn(run); err != nil {
+			return nil, err
+		}
+		if req.Method == http.MethodDelete || (req.Method == http.MethodPost && !req.Wait) {
+			return nil, nil
+		}
+
+		id := req.MessageID
+		if id == "" {
+			mu.Lock()
+			n++
+			id = strconv.Itoa(n)
+			mu.Unlock()
+		}
+		return dryRunMessage(id, c.WebhookURL.ID(), req.Payload), nil
+	})
+}
+
+// readAttachments lit le contenu des fichiers joints
+func readAttachments(attachments []Attachment) ([]DryRunFile, error) {
+	files := make([]DryRunFile, len(attachments))
+	for i, attachment := range attachments {
+		files[i] = DryRunFile{Name: attachment.filename(), Data: attachment.Data}
+		if attachment.Data == nil && attachment.Path != "" {
+			data, err := os.ReadFile(attachment.Path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open file: %w", err)
+			}
+			files[i].Data = data
+		}
+	}
+	return files, nil
+}
+
+// dryRunMessage construit le message que Discord aurait retourné
+func dryRunMessage(id, webhookID string, payload DiscordPayload) *Message {
+	message := &Message{
+		ID:         id,
+		WebhookID:  webhookID,
+		Content:    payload.Content,
+		Timestamp:  time.Now(),
+		TTS:        payload.TTS,
+		Embeds:     payload.Embeds,
+		Components: payload.Components,
+		Flags:      payload.Flags,
+		Poll:       payload.Poll,
+	}
+	for _, file := range payload.Files {
+		message.Attachments = append(message.Attachments, MessageAttachment{Filename: file.filename()})
+	}
+	return message
+}
@@ -0,0 +1,57 @@
+package discordwebhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// APIError est retournée lorsque Discord répond avec un statut d'erreur
+type APIError struct {
+	StatusCode int
+	Status     string
+	// Code est le code d'erreur JSON de Discord, ex: 10015 pour un webhook
+	// inconnu, 0 si la réponse n'en contient pas
+	Code    int
+	Message string
+	// Errors détaille les champs rejetés, tel que retourné par Discord
+	Errors json.RawMessage
+}
+
+func (e *APIError) Error() string {
+	msg := "webhook failed with status: " + e.Status
+	if e.Message != "" {
+		msg += fmt.Sprintf(": %s (code %d)", e.Message, e.Code)
+	}
+	return msg
+}
+
+// newAPIError construit une APIError à partir d'une réponse d'erreur
+func newAPIError(statusCode int, status string, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode, Status: status}
+	var decoded struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Errors  json.RawMessage `json:"errors"`
+	}
+	if json.Unmarshal(body, &decoded) == nil {
+		apiErr.Code, apiErr.Message, apiErr.Errors = decoded.Code, decoded.Message, decoded.Errors
+	}
+	return apiErr
+}
+
+// RateLimitError est retournée lorsqu'une requête reste limitée par Discord
+// après le nombre de tentatives autorisé par WithMaxRetries
+type RateLimitError struct {
+	// RetryAfter est l'attente demandée par Discord
+	RetryAfter time.Duration
+	RateLimit  RateLimit
+}
+
+func (e *RateLimitError) Error() string {
+	scope := ""
+	if e.RateLimit.Global {
+		scope = "globally "
+	}
+	return fmt.Sprintf("webhook %srate limited, retry after %s", scope, e.RetryAfter)
+}
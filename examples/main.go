@@ -59,6 +59,12 @@ func main() {
 	// Example 14: Different color usage
 	colorUsageExample(webhookURL)
 
+	// Example 15: Fluent builder
+	builderExample(webhookURL)
+
+	// Example 16: Link buttons
+	linkButtonsExample(webhookURL)
+
 	fmt.Println("\nAll examples completed!")
 }
 
@@ -104,7 +110,7 @@ func simpleEmbedExample(webhookURL string) {
 		Title:       "Simple Embed",
 		Description: "This is a simple embed with title and description.",
 		Color:       0x3498db, // Blue
-		Timestamp:   time.Now().Format(time.RFC3339),
+		Timestamp:   time.Now(),
 	}
 
 	err := client.SendEmbed(embed)
@@ -126,7 +132,7 @@ func completeEmbedExample(webhookURL string) {
 		Description: "This embed demonstrates all available fields including author, fields, image, thumbnail, and footer.",
 		Color:       0xe74c3c, // Red
 
-		Author: discordwebhook.EmbedAuthor{
+		Author: &discordwebhook.EmbedAuthor{
 			Name:    "GitHub",
 			URL:     "https://github.com",
 			IconURL: "https://github.com/favicon.ico",
@@ -155,12 +161,10 @@ func completeEmbedExample(webhookURL string) {
 			},
 		},
 
-		Image: map[string]string{
-			"url": "https://via.placeholder.com/400x200/3498db/ffffff?text=Main+Image",
-		},
+		Image: discordwebhook.Media("https://via.placeholder.com/400x200/3498db/ffffff?text=Main+Image"),
 
-		Thumbnail: map[string]string{
-			"url": "https://via.placeholder.com/80x80/e74c3c/ffffff?text=Thumb",
+		Thumbnail: &discordwebhook.EmbedMedia{
+			URL: "https://via.placeholder.com/80x80/e74c3c/ffffff?text=Thumb",
 		},
 
 		Footer: &discordwebhook.EmbedFooter{
@@ -168,7 +172,7 @@ func completeEmbedExample(webhookURL string) {
 			IconURL: "https://via.placeholder.com/20x20/95a5a6/ffffff?text=F",
 		},
 
-		Timestamp: time.Now().Format(time.RFC3339),
+		Timestamp: time.Now(),
 	}
 
 	err := client.SendEmbed(embed)
@@ -278,7 +282,7 @@ Uptime: 99.9%`
 				Inline: true,
 			},
 		},
-		Timestamp: time.Now().Format(time.RFC3339),
+		Timestamp: time.Now(),
 	}
 
 	err = client.SendEmbedWithFile(embed, "report.txt")
@@ -364,7 +368,7 @@ func errorNotificationExample(webhookURL string) {
 		Footer: &discordwebhook.EmbedFooter{
 			Text: "Error Monitoring System",
 		},
-		Timestamp: time.Now().Format(time.RFC3339),
+		Timestamp: time.Now(),
 	}
 
 	err := client.SendEmbed(embed)
@@ -435,7 +439,7 @@ func systemMonitoringExample(webhookURL string) {
 		Footer: &discordwebhook.EmbedFooter{
 			Text: "System Monitor v2.1",
 		},
-		Timestamp: time.Now().Format(time.RFC3339),
+		Timestamp: time.Now(),
 	}
 
 	err := client.SendEmbed(embed)
@@ -502,7 +506,7 @@ func deploymentNotificationExample(webhookURL string) {
 		Footer: &discordwebhook.EmbedFooter{
 			Text: "CI/CD Pipeline",
 		},
-		Timestamp: time.Now().Format(time.RFC3339),
+		Timestamp: time.Now(),
 	}
 
 	err := client.SendEmbed(embed)
@@ -526,10 +530,9 @@ func proxyConfigurationExample(webhookURL string) {
 
 	client := discordwebhook.NewClient(
 		webhookURL,
-		discordwebhook.WebhookOptions{
-			Username: "Proxy Bot",
-			Proxy:    proxyURL,
-		},
+		discordwebhook.WebhookOptions{Username: "Proxy Bot"},
+		discordwebhook.WithProxy(proxyURL),
+		discordwebhook.WithTimeout(10*time.Second),
 	)
 
 	// This would fail with the example proxy, but demonstrates the setup
@@ -585,7 +588,7 @@ func colorUsageExample(webhookURL string) {
 		Description: "Different colors available for embeds",
 		Color:       0x3498db,
 		Fields:      []discordwebhook.EmbedField{},
-		Timestamp:   time.Now().Format(time.RFC3339),
+		Timestamp:   time.Now(),
 	}
 
 	for _, color := range colors {
@@ -603,3 +606,58 @@ func colorUsageExample(webhookURL string) {
 		fmt.Println("✓ Color usage example sent successfully")
 	}
 }
+
+// Example 15: Fluent builder
+func builderExample(webhookURL string) {
+	fmt.Println("\n15. Builder Example")
+	client := discordwebhook.NewClient(webhookURL)
+
+	payload, err := discordwebhook.NewMessage().
+		Content("Nightly build finished").
+		Embed(discordwebhook.NewEmbed().
+			Title("Build #1234").
+			Description("All checks passed.").
+			Color(0x2ecc71).
+			InlineField("Duration", "4m 12s").
+			InlineField("Tests", "1,024").
+			Thumbnail("https://cdn.discordapp.com/embed/avatars/0.png").
+			Footer("CI/CD Pipeline", "").
+			Timestamp(time.Now())).
+		Build()
+	if err != nil {
+		log.Printf("Invalid payload: %v", err)
+		return
+	}
+
+	err = client.SendCustomPayload(payload)
+	if err != nil {
+		log.Printf("Error sending built payload: %v", err)
+	} else {
+		fmt.Println("✓ Built payload sent successfully")
+	}
+}
+
+// Example 16: Link buttons
+func linkButtonsExample(webhookURL string) {
+	fmt.Println("\n16. Link Buttons Example")
+	client := discordwebhook.NewClient(webhookURL)
+
+	payload, err := discordwebhook.NewMessage().
+		Content("🚀 v1.2.3 deployed to production").
+		LinkButtons(
+			discordwebhook.LinkButton("View logs", "https://example.com/logs/1234"),
+			discordwebhook.LinkButton("Open dashboard", "https://example.com/dashboard"),
+		).
+		Build()
+	if err != nil {
+		log.Printf("Invalid payload: %v", err)
+		return
+	}
+
+	err = client.SendCustomPayload(payload)
+	if err != nil {
+		log.Printf("Error sending link buttons: %v", err)
+	} else {
+		fmt.Println("✓ Link buttons sent successfully")
+	}
+}
@@ -0,0 +1,175 @@
+package discordwebhook
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ellipsis termine les textes tronqués par Fit
+const ellipsis = "…"
+
+// FitAction décrit le type de modification appliquée par Fit
+type FitAction string
+
+const (
+	FitTruncated FitAction = "truncated"
+	FitDropped   FitAction = "dropped"
+	FitMoved     FitAction = "moved"
+)
+
+// FitChange décrit une modification appliquée au payload par Fit
+type FitChange struct {
+	// Path désigne l'élément modifié, ex: "embeds[0].fields[3].value"
+	Path   string
+	Action FitAction
+	// Before et After contiennent la longueur (ou le nombre d'éléments, ou
+	// l'index du message pour FitMoved) avant et après la modification
+	Before int
+	After  int
+}
+
+func (c FitChange) String() string {
+	return fmt.Sprintf("%s %s (%d -> %d)", c.Path, c.Action, c.Before, c.After)
+}
+
+// FitReport liste les modifications appliquées par Fit
+type FitReport struct {
+	Changes []FitChange
+}
+
+// Changed indique si Fit a dû modifier le payload
+func (r FitReport) Changed() bool {
+	return len(r.Changes) > 0
+}
+
+func (r FitReport) String() string {
+	lines := make([]string, len(r.Changes))
+	for i, change := range r.Changes {
+		lines[i] = change.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *FitReport) add(path string, action FitAction, before, after int) {
+	r.Changes = append(r.Changes, FitChange{Path: path, Action: action, Before: before, After: after})
+}
+
+// Fit ajuste le payload aux limites de Discord au lieu d'échouer à l'envoi.
+// Les textes trop longs sont tronqués avec une ellipse, les champs en trop sont
+// remplacés par un champ "+N more" et les embeds qui ne tiennent pas dans le
+// premier message sont déplacés dans des messages de suite, qui ne contiennent
+// que des embeds. Le premier élément retourné est le payload d'origine ajusté,
+// le payload d'origine n'est pas modifié.
+func (p DiscordPayload) Fit() ([]DiscordPayload, FitReport) {
+	var report FitReport
+
+	first := p
+	first.Content = truncateField(&report, "content", p.Content, MaxContentLength)
+	first.Username = truncateField(&report, "username", p.Username, MaxUsernameLength)
+	first.Embeds = nil
+
+	// Les messages de suite ne reprennent que l'identité du webhook et la
+	// politique de notification : le contenu, les fichiers, les composants et
+	// le sondage n'apparaissent que dans le premier message
+	followUp := DiscordPayload{
+		Username:        first.Username,
+		Avatar:          first.Avatar,
+		AllowedMentions: first.AllowedMentions,
+		Flags:           first.Flags &^ FlagIsComponentsV2,
+	}
+
+	messages := []DiscordPayload{first}
+	for i, embed := range p.Embeds {
+		embed = fitEmbed(&report, fmt.Sprintf("embeds[%d]", i), embed)
+
+		last := &messages[len(messages)-1]
+		if len(last.Embeds) >= MaxEmbedsPerMessage || embedsLength(last.Embeds)+embed.Length() > MaxEmbedTotalLength {
+			messages = append(messages, followUp)
+			last = &messages[len(messages)-1]
+		}
+		last.Embeds = append(last.Embeds, embed)
+
+		if len(messages) > 1 {
+			report.add(fmt.Sprintf("embeds[%d]", i), FitMoved, 0, len(messages)-1)
+		}
+	}
+
+	return messages, report
+}
+
+// fitEmbed retourne une copie de l'embed respectant les limites de Discord
+func fitEmbed(report *FitReport, path string, embed DiscordEmbed) DiscordEmbed {
+	embed.Title = truncateField(report, path+".title", embed.Title, MaxEmbedTitleLength)
+	embed.Description = truncateField(report, path+".description", embed.Description, MaxEmbedDescriptionLength)
+	if embed.Author != nil {
+		author := *embed.Author
+		author.Name = truncateField(report, path+".author.name", author.Name, MaxEmbedAuthorNameLength)
+		embed.Author = &author
+	}
+	if embed.Footer != nil {
+		footer := *embed.Footer
+		footer.Text = truncateField(report, path+".footer.text", footer.Text, MaxEmbedFooterLength)
+		embed.Footer = &footer
+	}
+
+	fields := make([]EmbedField, len(embed.Fields))
+	for i, field := range embed.Fields {
+		fieldPath := fmt.Sprintf("%s.fields[%d]", path, i)
+		field.Name = truncateField(report, fieldPath+".name", field.Name, MaxEmbedFieldNameLength)
+		field.Value = truncateField(report, fieldPath+".value", field.Value, MaxEmbedFieldValueLength)
+		fields[i] = field
+	}
+
+	// Les champs en trop sont remplacés par un champ récapitulatif, puis les
+	// derniers champs sont retirés tant que l'embed dépasse la taille totale
+	hidden := 0
+	if len(fields) > MaxEmbedFields {
+		hidden = len(fields) - (MaxEmbedFields - 1)
+		fields = fields[:MaxEmbedFields-1]
+	}
+	embed.Fields = withMoreField(fields, hidden)
+	for embed.Length() > MaxEmbedTotalLength && len(fields) > 0 {
+		fields = fields[:len(fields)-1]
+		hidden++
+		embed.Fields = withMoreField(fields, hidden)
+	}
+	if hidden > 0 {
+		report.add(path+".fields", FitDropped, len(fields)+hidden, len(fields))
+	}
+
+	if over := embed.Length() - MaxEmbedTotalLength; over > 0 {
+		embed.Description = truncateField(report, path+".description", embed.Description, runeCount(embed.Description)-over)
+	}
+
+	return embed
+}
+
+// withMoreField ajoute un champ "+N more" lorsque des champs ont été retirés
+func withMoreField(fields []EmbedField, hidden int) []EmbedField {
+	if hidden == 0 {
+		return fields
+	}
+	more := EmbedField{Name: ellipsis, Value: fmt.Sprintf("+%d more", hidden)}
+	return append(fields[:len(fields):len(fields)], more)
+}
+
+// truncateField tronque s à max caractères et consigne la modification
+func truncateField(report *FitReport, path, s string, max int) string {
+	truncated, ok := truncate(s, max)
+	if ok {
+		report.add(path, FitTruncated, runeCount(s), runeCount(truncated))
+	}
+	return truncated
+}
+
+// truncate tronque s à max caractères (runes) en terminant par une ellipse
+func truncate(s string, max int) (string, bool) {
+	if runeCount(s) <= max {
+		return s, false
+	}
+	if max <= 0 {
+		return "", true
+	}
+	runes := []rune(s)
+	return string(runes[:max-1]) + ellipsis, true
+}
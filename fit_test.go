@@ -0,0 +1,148 @@
+package discordwebhook_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	discordwebhook "github.com/peepsii/discord-webhook-go"
+)
+
+func TestFit(t *testing.T) {
+	fields := make([]discordwebhook.EmbedField, 30)
+	for i := range fields {
+		fields[i] = discordwebhook.EmbedField{Name: fmt.Sprintf("field %d", i), Value: "value"}
+	}
+	poll := discordwebhook.NewPoll("Ship it?", "Yes", "No")
+	embeds := func(n int, description string) []discordwebhook.DiscordEmbed {
+		embeds := make([]discordwebhook.DiscordEmbed, n)
+		for i := range embeds {
+			embeds[i] = discordwebhook.DiscordEmbed{Title: fmt.Sprintf("embed %d", i), Description: description}
+		}
+		return embeds
+	}
+
+	tests := []struct {
+		name     string
+		payload  discordwebhook.DiscordPayload
+		embeds   []int
+		changes  []discordwebhook.FitChange
+		validate func(t *testing.T, messages []discordwebhook.DiscordPayload)
+	}{
+		{
+			name:    "within limits",
+			payload: discordwebhook.DiscordPayload{Content: "hello", Embeds: embeds(2, "short")},
+			embeds:  []int{2},
+		},
+		{
+			name:    "long content",
+			payload: discordwebhook.DiscordPayload{Content: strings.Repeat("é", 2100)},
+			embeds:  []int{0},
+			changes: []discordwebhook.FitChange{{Path: "content", Action: discordwebhook.FitTruncated, Before: 2100, After: 2000}},
+			validate: func(t *testing.T, messages []discordwebhook.DiscordPayload) {
+				if !strings.HasSuffix(messages[0].Content, "…") {
+					t.Errorf("content does not end with an ellipsis")
+				}
+			},
+		},
+		{
+			name: "long embed texts",
+			payload: discordwebhook.DiscordPayload{Embeds: []discordwebhook.DiscordEmbed{{
+				Title:  strings.Repeat("t", 300),
+				Footer: &discordwebhook.EmbedFooter{Text: strings.Repeat("f", 2100)},
+			}}},
+			embeds: []int{1},
+			changes: []discordwebhook.FitChange{
+				{Path: "embeds[0].title", Action: discordwebhook.FitTruncated, Before: 300, After: 256},
+				{Path: "embeds[0].footer.text", Action: discordwebhook.FitTruncated, Before: 2100, After: 2048},
+			},
+		},
+		{
+			name:    "too many fields",
+			payload: discordwebhook.DiscordPayload{Embeds: []discordwebhook.DiscordEmbed{{Title: "fields", Fields: fields}}},
+			embeds:  []int{1},
+			changes: []discordwebhook.FitChange{{Path: "embeds[0].fields", Action: discordwebhook.FitDropped, Before: 30, After: 24}},
+			validate: func(t *testing.T, messages []discordwebhook.DiscordPayload) {
+				got := messages[0].Embeds[0].Fields
+				if len(got) != 25 || got[24].Value != "+6 more" {
+					t.Errorf("got %d fields ending with %+v, want 25 ending with +6 more", len(got), got[len(got)-1])
+				}
+			},
+		},
+		{
+			name:    "too many embeds",
+			payload: discordwebhook.DiscordPayload{Embeds: embeds(12, "short")},
+			embeds:  []int{10, 2},
+			changes: []discordwebhook.FitChange{
+				{Path: "embeds[10]", Action: discordwebhook.FitMoved, Before: 0, After: 1},
+				{Path: "embeds[11]", Action: discordwebhook.FitMoved, Before: 0, After: 1},
+			},
+		},
+		{
+			name:    "embeds over the total length",
+			payload: discordwebhook.DiscordPayload{Embeds: embeds(3, strings.Repeat("d", 2500))},
+			embeds:  []int{2, 1},
+			changes: []discordwebhook.FitChange{{Path: "embeds[2]", Action: discordwebhook.FitMoved, Before: 0, After: 1}},
+		},
+		{
+			name: "follow-ups carry only embeds",
+			payload: discordwebhook.DiscordPayload{
+				Content:         "deploy",
+				Username:        "Bot",
+				Avatar:          "https://example.com/a.png",
+				AllowedMentions: discordwebhook.NoMentions(),
+				Flags:           discordwebhook.FlagSuppressNotifications,
+				Files:           []discordwebhook.Attachment{{Name: "log.txt", Data: []byte("log")}},
+				Poll:            &poll,
+				Embeds:          embeds(11, "short"),
+			},
+			embeds:  []int{10, 1},
+			changes: []discordwebhook.FitChange{{Path: "embeds[10]", Action: discordwebhook.FitMoved, Before: 0, After: 1}},
+			validate: func(t *testing.T, messages []discordwebhook.DiscordPayload) {
+				followUp := messages[1]
+				followUp.Embeds = nil
+				want := discordwebhook.DiscordPayload{
+					Username:        "Bot",
+					Avatar:          "https://example.com/a.png",
+					AllowedMentions: discordwebhook.NoMentions(),
+					Flags:           discordwebhook.FlagSuppressNotifications,
+				}
+				if !reflect.DeepEqual(followUp, want) {
+					t.Errorf("follow-up = %+v, want %+v", followUp, want)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := tt.payload
+			original.Embeds = append([]discordwebhook.DiscordEmbed(nil), tt.payload.Embeds...)
+
+			messages, report := tt.payload.Fit()
+			if !reflect.DeepEqual(tt.payload, original) {
+				t.Errorf("Fit() modified the payload")
+			}
+			if !reflect.DeepEqual(report.Changes, tt.changes) {
+				t.Errorf("changes = %v, want %v", report.Changes, tt.changes)
+			}
+			if report.Changed() != (len(tt.changes) > 0) {
+				t.Errorf("Changed() = %v", report.Changed())
+			}
+
+			var counts []int
+			for i, message := range messages {
+				counts = append(counts, len(message.Embeds))
+				if err := message.Validate(); err != nil {
+					t.Errorf("message %d: %v", i, err)
+				}
+			}
+			if !reflect.DeepEqual(counts, tt.embeds) {
+				t.Errorf("embeds per message = %v, want %v", counts, tt.embeds)
+			}
+			if tt.validate != nil {
+				tt.validate(t, messages)
+			}
+		})
+	}
+}
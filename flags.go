@@ -0,0 +1,56 @@
+package discordwebhook
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MessageFlags représente le champ de bits flags d'un message Discord
+type MessageFlags int
+
+// Flags acceptés par Discord lors de l'envoi d'un message par webhook
+const (
+	// FlagSuppressEmbeds désactive les aperçus des liens du message
+	FlagSuppressEmbeds MessageFlags = 1 << 2
+	// FlagSuppressNotifications envoie le message sans notification (@silent)
+	FlagSuppressNotifications MessageFlags = 1 << 12
+	// FlagIsComponentsV2 active les composants de mise en page, le message ne
+	// peut alors plus contenir de texte ni d'embeds
+	FlagIsComponentsV2 MessageFlags = 1 << 15
+)
+
+// webhookFlags regroupe les flags qu'un webhook est autorisé à envoyer
+const webhookFlags = FlagSuppressEmbeds | FlagSuppressNotifications | FlagIsComponentsV2
+
+var flagNames = []struct {
+	flag MessageFlags
+	name string
+}{
+	{FlagSuppressEmbeds, "SUPPRESS_EMBEDS"},
+	{FlagSuppressNotifications, "SUPPRESS_NOTIFICATIONS"},
+	{FlagIsComponentsV2, "IS_COMPONENTS_V2"},
+}
+
+// Has indique si tous les flags de flag sont positionnés
+func (f MessageFlags) Has(flag MessageFlags) bool {
+	return f&flag == flag
+}
+
+func (f MessageFlags) String() string {
+	if f == 0 {
+		return "0"
+	}
+
+	var names []string
+	rest := f
+	for _, n := range flagNames {
+		if f.Has(n.flag) {
+			names = append(names, n.name)
+			rest &^= n.flag
+		}
+	}
+	if rest != 0 {
+		names = append(names, fmt.Sprintf("%#x", int(rest)))
+	}
+	return strings.Join(names, "|")
+}
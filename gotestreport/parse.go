@@ -0,0 +1,412 @@
+// Package gotestreport résume la sortie de go test -json et publie le
+// résultat sur un webhook Discord. La commande discord-webhook gotest
+// l'utilise pour lire la sortie de go test sur stdin
+package gotestreport
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrNoEvents est retournée par Parse lorsque l'entrée ne contient aucun
+// événement go test -json
+var ErrNoEvents = errors.New("no go test -json events")
+
+// Statuts d'un test ou d'un paquet
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// Test est le résultat d'un test ou d'un sous-test
+type Test struct {
+	Package string
+	Name    string
+	// Status vaut StatusPass, StatusFail ou StatusSkip, vide si le test ne
+	// s'est pas terminé
+	Status  string
+	Elapsed time.Duration
+	// Output est la sortie du test, sans les lignes === RUN et --- FAIL. Celle
+	// d'un parent en échec, ex: la trace d'une panique du sous-test, y est
+	// ajoutée
+	Output string
+}
+
+// Package est le résultat des tests d'un paquet. Les compteurs ne portent que
+// sur les tests sans sous-tests, un test parent en échec sans sous-test en
+// échec étant toutefois compté. Avec go test -count, chaque exécution d'un
+// test est comptée
+type Package struct {
+	Name    string
+	Status  string
+	Elapsed time.Duration
+	Passed  int
+	Failed  int
+	Skipped int
+	// Failures contient les tests en échec, un test dont un sous-test a
+	// échoué étant omis au profit de ce dernier
+	Failures []*Test
+	// Output est la sortie non rattachée à un test, ex: erreurs de
+	// compilation ou panique hors d'un test
+	Output string
+}
+
+// Report agrège les résultats de go test -json
+type Report struct {
+	Packages []*Package
+	Passed   int
+	Failed   int
+	Skipped  int
+	// Elapsed est la durée entre le premier et le dernier événement
+	Elapsed time.Duration
+	// Output contient les lignes de l'entrée qui ne sont pas des événements
+	// JSON, ex: erreurs de go vet écrites sur stderr
+	Output string
+}
+
+// event est un événement de go test -json, voir go doc test2json
+type event struct {
+	Time        time.Time
+	Action      string
+	Package     string
+	Test        string
+	Elapsed     float64
+	Output      string
+	ImportPath  string
+	FailedBuild string
+}
+
+// parser conserve l'état de l'analyse
+type parser struct {
+	report   Report
+	packages map[string]*Package
+	tests    map[[2]string]*Test
+	parents  map[*Test]bool
+	parent   map[*Test]*Test
+	running  map[string][]*Test
+	outputs  map[*Test]*strings.Builder
+	pkgOut   map[string]*strings.Builder
+	builds   map[string]*strings.Builder
+	stray    strings.Builder
+	first    time.Time
+	last     time.Time
+	events   int
+}
+
+// Parse lit la sortie de go test -json. Les lignes qui ne sont pas des
+// événements sont conservées dans Report.Output
+func Parse(r io.Reader) (*Report, error) {
+	p := &parser{
+		packages: make(map[string]*Package),
+		tests:    make(map[[2]string]*Test),
+		parents:  make(map[*Test]bool),
+		parent:   make(map[*Test]*Test),
+		running:  make(map[string][]*Test),
+		outputs:  make(map[*Test]*strings.Builder),
+		pkgOut:   make(map[string]*strings.Builder),
+		builds:   make(map[string]*strings.Builder),
+	}
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			p.line(line)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read test output: %w", err)
+		}
+	}
+	if p.events == 0 {
+		return nil, ErrNoEvents
+	}
+	return p.finish(), nil
+}
+
+func (p *parser) line(line string) {
+	var e event
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "{") || json.Unmarshal([]byte(trimmed), &e) != nil || e.Action == "" {
+		if trimmed != "" {
+			p.stray.WriteString(strings.TrimRight(line, "\r\n") + "\n")
+		}
+		return
+	}
+
+	p.events++
+	if !e.Time.IsZero() {
+		if p.first.IsZero() {
+			p.first = e.Time
+		}
+		p.last = e.Time
+	}
+
+	switch e.Action {
+	case "build-output":
+		builder(p.builds, e.ImportPath).WriteString(e.Output)
+		return
+	case "build-fail":
+		return
+	}
+	if e.Package == "" {
+		return
+	}
+
+	pkg := p.pkg(e.Package)
+	if e.Test == "" {
+		p.packageEvent(pkg, e)
+		return
+	}
+	p.testEvent(pkg, e)
+}
+
+func (p *parser) packageEvent(pkg *Package, e event) {
+	switch e.Action {
+	case "output":
+		builder(p.pkgOut, pkg.Name).WriteString(e.Output)
+	case StatusPass, StatusFail, StatusSkip:
+		pkg.Status = e.Action
+		pkg.Elapsed = seconds(e.Elapsed)
+		if e.FailedBuild != "" {
+			if out, ok := p.builds[e.FailedBuild]; ok {
+				builder(p.pkgOut, pkg.Name).WriteString(out.String())
+			}
+		}
+		if e.Action == StatusFail {
+			// Les tests encore en cours ont été interrompus, ex: panique ou
+			// dépassement du délai. Les sous-tests, démarrés après leur
+			// parent, sont traités en premier
+			running := p.running[pkg.Name]
+			for i := len(running) - 1; i >= 0; i-- {
+				if running[i].Status == "" {
+					p.fail(pkg, running[i])
+				}
+			}
+		}
+		delete(p.running, pkg.Name)
+	}
+}
+
+func (p *parser) testEvent(pkg *Package, e event) {
+	key := [2]string{e.Package, e.Test}
+	test, ok := p.tests[key]
+	// Avec go test -count, un test déjà terminé est exécuté à nouveau
+	if !ok || e.Action == "run" && test.Status != "" {
+		test = &Test{Package: e.Package, Name: e.Test}
+		p.tests[key] = test
+		p.running[pkg.Name] = append(p.running[pkg.Name], test)
+		// Les tests parents ne sont pas comptés, seuls leurs sous-tests le sont
+		for name := e.Test; strings.Contains(name, "/"); {
+			name = name[:strings.LastIndex(name, "/")]
+			if parent, ok := p.tests[[2]string{e.Package, name}]; ok {
+				p.parents[parent] = true
+				if p.parent[test] == nil {
+					p.parent[test] = parent
+				}
+			}
+		}
+	}
+	if test.Status != "" {
+		return
+	}
+
+	switch e.Action {
+	case "output":
+		builder(p.outputs, test).WriteString(e.Output)
+	case StatusPass:
+		test.Status, test.Elapsed = e.Action, seconds(e.Elapsed)
+		if !p.parents[test] {
+			pkg.Passed++
+		}
+	case StatusSkip:
+		test.Status, test.Elapsed = e.Action, seconds(e.Elapsed)
+		if !p.parents[test] {
+			pkg.Skipped++
+		}
+	case StatusFail:
+		test.Elapsed = seconds(e.Elapsed)
+		p.fail(pkg, test)
+	}
+}
+
+// fail enregistre l'échec de test. Un test dont un sous-test a échoué n'est
+// ni compté ni listé, l'échec étant déjà rapporté par le sous-test. Sa sortie,
+// où go test écrit la trace d'une panique du sous-test, est ajoutée à celle du
+// dernier sous-test en échec
+func (p *parser) fail(pkg *Package, test *Test) {
+	if test.Status == StatusFail {
+		return
+	}
+	test.Status = StatusFail
+	if out, ok := p.outputs[test]; ok {
+		test.Output = cleanOutput(out.String())
+	}
+	for i := len(pkg.Failures) - 1; i >= 0; i-- {
+		if failure := pkg.Failures[i]; p.descends(failure, test) {
+			if failure.Output != "" && test.Output != "" {
+				failure.Output += "\n"
+			}
+			failure.Output += test.Output
+			return
+		}
+	}
+	pkg.Failed++
+	pkg.Failures = append(pkg.Failures, test)
+}
+
+// descends indique si test est un sous-test de l'exécution ancestor
+func (p *parser) descends(test, ancestor *Test) bool {
+	for test = p.parent[test]; test != nil; test = p.parent[test] {
+		if test == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) pkg(name string) *Package {
+	pkg, ok := p.packages[name]
+	if !ok {
+		pkg = &Package{Name: name}
+		p.packages[name] = pkg
+		p.report.Packages = append(p.report.Packages, pkg)
+	}
+	return pkg
+}
+
+func (p *parser) finish() *Report {
+	r := &p.report
+	var elapsed time.Duration
+	for _, pkg := range r.Packages {
+		if out, ok := p.pkgOut[pkg.Name]; ok && pkg.Status == StatusFail {
+			pkg.Output = cleanOutput(out.String())
+		}
+		r.Passed += pkg.Passed
+		r.Failed += pkg.Failed
+		r.Skipped += pkg.Skipped
+		elapsed += pkg.Elapsed
+	}
+	// Sans horodatage, les durées des paquets sont additionnées
+	r.Elapsed = p.last.Sub(p.first)
+	if r.Elapsed == 0 {
+		r.Elapsed = elapsed
+	}
+	r.Output = p.stray.String()
+	return r
+}
+
+// OK indique qu'aucun test ni paquet n'a échoué
+func (r *Report) OK() bool {
+	if r.Failed > 0 {
+		return false
+	}
+	for _, pkg := range r.Packages {
+		if pkg.Status == StatusFail {
+			return false
+		}
+	}
+	return true
+}
+
+// FailedPackages retourne les paquets en échec
+func (r *Report) FailedPackages() []*Package {
+	var failed []*Package
+	for _, pkg := range r.Packages {
+		if pkg.Status == StatusFail || len(pkg.Failures) > 0 {
+			failed = append(failed, pkg)
+		}
+	}
+	return failed
+}
+
+// Log retourne la sortie complète des tests et paquets en échec
+func (r *Report) Log() string {
+	var b strings.Builder
+	for _, pkg := range r.FailedPackages() {
+		for _, test := range pkg.Failures {
+			fmt.Fprintf(&b, "--- FAIL: %s (%s) (%s)\n", test.Name, pkg.Name, test.Elapsed)
+			writeIndented(&b, test.Output)
+		}
+		if pkg.Output != "" {
+			fmt.Fprintf(&b, "--- FAIL: %s\n", pkg.Name)
+			writeIndented(&b, pkg.Output)
+		}
+	}
+	if r.Output != "" {
+		b.WriteString("--- Output\n")
+		writeIndented(&b, r.Output)
+	}
+	return b.String()
+}
+
+func writeIndented(b *strings.Builder, text string) {
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if line != "" {
+			b.WriteString("    ")
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteByte('\n')
+}
+
+// cleanOutput retire les lignes de déroulement ajoutées par go test ainsi que
+// l'indentation commune
+func cleanOutput(output string) string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if isFraming(strings.TrimSpace(line)) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			lines[i] = line[indent:]
+		}
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// isFraming indique si line est une ligne de déroulement de go test
+func isFraming(line string) bool {
+	for _, prefix := range []string{"=== RUN", "=== PAUSE", "=== CONT", "=== NAME", "--- FAIL:", "--- PASS:", "--- SKIP:"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return line == "PASS" || line == "FAIL" || strings.HasPrefix(line, "FAIL\t") || strings.HasPrefix(line, "ok  \t")
+}
+
+func builder[K comparable](m map[K]*strings.Builder, key K) *strings.Builder {
+	b, ok := m[key]
+	if !ok {
+		b = &strings.Builder{}
+		m[key] = b
+	}
+	return b
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second)).Round(time.Millisecond)
+}
@@ -0,0 +1,190 @@
+package gotestreport_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/peepsii/discord-webhook-go/gotestreport"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		status   string
+		passed   int
+		failed   int
+		skipped  int
+		failures []string
+		output   string
+		stray    string
+	}{
+		{
+			name: "pass, fail and skip",
+			input: []string{
+				`{"Action":"start","Package":"example.com/p"}`,
+				`{"Action":"run","Package":"example.com/p","Test":"TestPass"}`,
+				`{"Action":"output","Package":"example.com/p","Test":"TestPass","Output":"=== RUN   TestPass\n"}`,
+				`{"Action":"output","Package":"example.com/p","Test":"TestPass","Output":"--- PASS: TestPass (0.00s)\n"}`,
+				`{"Action":"pass","Package":"example.com/p","Test":"TestPass","Elapsed":0}`,
+				`{"Action":"run","Package":"example.com/p","Test":"TestSkip"}`,
+				`{"Action":"skip","Package":"example.com/p","Test":"TestSkip","Elapsed":0}`,
+				`{"Action":"run","Package":"example.com/p","Test":"TestFail"}`,
+				`{"Action":"output","Package":"example.com/p","Test":"TestFail","Output":"=== RUN   TestFail\n"}`,
+				`{"Action":"output","Package":"example.com/p","Test":"TestFail","Output":"    p_test.go:12: got 1, want 2\n"}`,
+				`{"Action":"output","Package":"example.com/p","Test":"TestFail","Output":"--- FAIL: TestFail (0.00s)\n"}`,
+				`{"Action":"fail","Package":"example.com/p","Test":"TestFail","Elapsed":0.01}`,
+				`{"Action":"output","Package":"example.com/p","Output":"FAIL\n"}`,
+				`{"Action":"fail","Package":"example.com/p","Elapsed":0.02}`,
+			},
+			status:   gotestreport.StatusFail,
+			passed:   1,
+			failed:   1,
+			skipped:  1,
+			failures: []string{"TestFail: p_test.go:12: got 1, want 2"},
+		},
+		{
+			name: "only subtests are counted",
+			input: []string{
+				`{"Action":"run","Package":"example.com/p","Test":"TestTable"}`,
+				`{"Action":"run","Package":"example.com/p","Test":"TestTable/one"}`,
+				`{"Action":"pass","Package":"example.com/p","Test":"TestTable/one","Elapsed":0}`,
+				`{"Action":"run","Package":"example.com/p","Test":"TestTable/two"}`,
+				`{"Action":"run","Package":"example.com/p","Test":"TestTable/two/nested"}`,
+				`{"Action":"skip","Package":"example.com/p","Test":"TestTable/two/nested","Elapsed":0}`,
+				`{"Action":"pass","Package":"example.com/p","Test":"TestTable/two","Elapsed":0}`,
+				`{"Action":"pass","Package":"example.com/p","Test":"TestTable","Elapsed":0}`,
+				`{"Action":"pass","Package":"example.com/p","Elapsed":0.01}`,
+			},
+			status:  gotestreport.StatusPass,
+			passed:  1,
+			skipped: 1,
+		},
+		{
+			name: "subtest panic",
+			input: []string{
+				`{"Action":"run","Package":"example.com/p","Test":"TestTable"}`,
+				`{"Action":"run","Package":"example.com/p","Test":"TestTable/nil"}`,
+				`{"Action":"output","Package":"example.com/p","Test":"TestTable/nil","Output":"=== RUN   TestTable/nil\n"}`,
+				`{"Action":"output","Package":"example.com/p","Test":"TestTable/nil","Output":"    --- FAIL: TestTable/nil (0.00s)\n"}`,
+				`{"Action":"fail","Package":"example.com/p","Test":"TestTable/nil","Elapsed":0}`,
+				`{"Action":"output","Package":"example.com/p","Test":"TestTable","Output":"--- FAIL: TestTable (0.00s)\n"}`,
+				`{"Action":"output","Package":"example.com/p","Test":"TestTable","Output":"panic: runtime error: invalid memory address [recovered]\n"}`,
+				`{"Action":"output","Package":"example.com/p","Test":"TestTable","Output":"\tpanic: runtime error: invalid memory address\n"}`,
+				`{"Action":"fail","Package":"example.com/p","Test":"TestTable","Elapsed":0}`,
+				`{"Action":"output","Package":"example.com/p","Output":"FAIL\texample.com/p\t0.01s\n"}`,
+				`{"Action":"fail","Package":"example.com/p","Elapsed":0.01}`,
+			},
+			status: gotestreport.StatusFail,
+			failed: 1,
+			failures: []string{
+				"TestTable/nil: panic: runtime error: invalid memory address [recovered]\n\tpanic: runtime error: invalid memory address",
+			},
+		},
+		{
+			name: "count reruns",
+			input: []string{
+				`{"Action":"run","Package":"example.com/p","Test":"TestFlaky"}`,
+				`{"Action":"pass","Package":"example.com/p","Test":"TestFlaky","Elapsed":0}`,
+				`{"Action":"run","Package":"example.com/p","Test":"TestFlaky"}`,
+				`{"Action":"output","Package":"example.com/p","Test":"TestFlaky","Output":"    p_test.go:8: timeout\n"}`,
+				`{"Action":"fail","Package":"example.com/p","Test":"TestFlaky","Elapsed":0}`,
+				`{"Action":"run","Package":"example.com/p","Test":"TestFlaky"}`,
+				`{"Action":"pass","Package":"example.com/p","Test":"TestFlaky","Elapsed":0}`,
+				`{"Action":"fail","Package":"example.com/p","Elapsed":0.01}`,
+			},
+			status:   gotestreport.StatusFail,
+			passed:   2,
+			failed:   1,
+			failures: []string{"TestFlaky: p_test.go:8: timeout"},
+		},
+		{
+			name: "interrupted tests",
+			input: []string{
+				`{"Action":"run","Package":"example.com/p","Test":"TestSlow"}`,
+				`{"Action":"run","Package":"example.com/p","Test":"TestSlow/wait"}`,
+				`{"Action":"output","Package":"example.com/p","Test":"TestSlow/wait","Output":"    p_test.go:20: waiting\n"}`,
+				`{"Action":"output","Package":"example.com/p","Output":"panic: test timed out after 1s\n"}`,
+				`{"Action":"output","Package":"example.com/p","Output":"FAIL\texample.com/p\t1.01s\n"}`,
+				`{"Action":"fail","Package":"example.com/p","Elapsed":1.01}`,
+			},
+			status:   gotestreport.StatusFail,
+			failed:   1,
+			failures: []string{"TestSlow/wait: p_test.go:20: waiting"},
+			output:   "panic: test timed out after 1s",
+		},
+		{
+			name: "build failure",
+			input: []string{
+				`{"ImportPath":"example.com/p [example.com/p.test]","Action":"build-output","Output":"# example.com/p [example.com/p.test]\n"}`,
+				`{"ImportPath":"example.com/p [example.com/p.test]","Action":"build-output","Output":"./p_test.go:3:1: syntax error\n"}`,
+				`{"ImportPath":"example.com/p [example.com/p.test]","Action":"build-fail"}`,
+				`{"Action":"start","Package":"example.com/p"}`,
+				`{"Action":"output","Package":"example.com/p","Output":"FAIL\texample.com/p [build failed]\n"}`,
+				`{"Action":"fail","Package":"example.com/p","Elapsed":0,"FailedBuild":"example.com/p [example.com/p.test]"}`,
+			},
+			status: gotestreport.StatusFail,
+			output: "# example.com/p [example.com/p.test]\n./p_test.go:3:1: syntax error",
+		},
+		{
+			name: "stray output",
+			input: []string{
+				`go: downloading example.com/dep v1.0.0`,
+				`{"Action":"run","Package":"example.com/p","Test":"TestPass"}`,
+				`{"Action":"pass","Package":"example.com/p","Test":"TestPass","Elapsed":0}`,
+				``,
+				`{"Action":"pass","Package":"example.com/p","Elapsed":0}`,
+				`{not json`,
+			},
+			status: gotestreport.StatusPass,
+			passed: 1,
+			stray:  "go: downloading example.com/dep v1.0.0\n{not json\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := gotestreport.Parse(strings.NewReader(strings.Join(tt.input, "\n") + "\n"))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(report.Packages) != 1 {
+				t.Fatalf("got %d packages, want 1", len(report.Packages))
+			}
+			pkg := report.Packages[0]
+			if pkg.Name != "example.com/p" || pkg.Status != tt.status {
+				t.Errorf("package %s status = %q, want %q", pkg.Name, pkg.Status, tt.status)
+			}
+			if report.Passed != tt.passed || report.Failed != tt.failed || report.Skipped != tt.skipped {
+				t.Errorf("passed, failed, skipped = %d, %d, %d, want %d, %d, %d",
+					report.Passed, report.Failed, report.Skipped, tt.passed, tt.failed, tt.skipped)
+			}
+
+			var failures []string
+			for _, test := range pkg.Failures {
+				failures = append(failures, test.Name+": "+test.Output)
+			}
+			if !reflect.DeepEqual(failures, tt.failures) {
+				t.Errorf("failures = %q, want %q", failures, tt.failures)
+			}
+			if pkg.Output != tt.output {
+				t.Errorf("package output = %q, want %q", pkg.Output, tt.output)
+			}
+			if report.Output != tt.stray {
+				t.Errorf("report output = %q, want %q", report.Output, tt.stray)
+			}
+			if report.OK() != (tt.status == gotestreport.StatusPass) {
+				t.Errorf("OK() = %v for a package status %q", report.OK(), tt.status)
+			}
+		})
+	}
+}
+
+func TestParseWithoutEvents(t *testing.T) {
+	for _, input := range []string{"", "ok  \texample.com/p\t0.01s\n"} {
+		if _, err := gotestreport.Parse(strings.NewReader(input)); !errors.Is(err, gotestreport.ErrNoEvents) {
+			t.Errorf("Parse(%q) error = %v, want ErrNoEvents", input, err)
+		}
+	}
+}
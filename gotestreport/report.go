@@ -0,0 +1,176 @@
+package gotestreport
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	discordwebhook "github.com/peepsii/discord-webhook-go"
+)
+
+// Couleurs de l'embed de résumé
+const (
+	ColorPass = discordwebhook.ColorSuccess
+	ColorFail = discordwebhook.ColorFailure
+)
+
+// LogFilename est le nom du fichier joint contenant la sortie complète des
+// échecs
+const LogFilename = "failures.txt"
+
+// DefaultTitle est le titre utilisé lorsque Payload reçoit un titre vide
+const DefaultTitle = "Go tests"
+
+// Post analyse la sortie de go test -json lue dans r et publie son résumé via
+// sender. Le rapport est retourné même si l'envoi échoue
+func Post(sender discordwebhook.Sender, r io.Reader, title string) (*Report, error) {
+	report, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	return report, sender.SendCustomPayload(report.Payload(title))
+}
+
+// Payload construit un embed résumant le rapport : les totaux, les paquets en
+// échec et un champ par test en échec avec la fin de sa sortie. Lorsque tous
+// les échecs ne tiennent pas dans les limites de Discord, la sortie complète
+// est jointe dans LogFilename
+func (r *Report) Payload(title string) discordwebhook.DiscordPayload {
+	if title == "" {
+		title = DefaultTitle
+	}
+	embed := discordwebhook.DiscordEmbed{
+		Title: discordwebhook.Truncate("✅ "+title+" passed", discordwebhook.MaxEmbedTitleLength),
+		Color: ColorPass,
+	}
+	if !r.OK() {
+		embed.Title = discordwebhook.Truncate("❌ "+title+" failed", discordwebhook.MaxEmbedTitleLength)
+		embed.Color = ColorFail
+	}
+
+	failed := r.FailedPackages()
+	description, complete := r.description(failed)
+	embed.Description = description
+
+	// Les extraits se partagent la place restante, le pied de page ajouté en
+	// cas de troncature étant réservé
+	budget := discordwebhook.MaxEmbedTotalLength - embed.Length() - 64
+	fields := r.fields(failed)
+	limit := discordwebhook.MaxEmbedFieldValueLength
+	if n := min(len(fields), discordwebhook.MaxEmbedFields); n > 0 {
+		limit = min(limit, max(minExcerpt, budget/n-64))
+	}
+	omitted := 0
+	for i, field := range fields {
+		value, truncated := excerpt(field.Value, limit)
+		n := len([]rune(field.Name)) + len([]rune(value))
+		if len(embed.Fields) == discordwebhook.MaxEmbedFields || n > budget {
+			omitted = len(fields) - i
+			break
+		}
+		complete = complete && !truncated
+		budget -= n
+		field.Value = value
+		embed.Fields = append(embed.Fields, field)
+	}
+
+	var files []discordwebhook.Attachment
+	if omitted > 0 || !complete {
+		footer := "Full failure log in " + LogFilename
+		if omitted > 0 {
+			footer = plural(omitted, "more failure") + ", full log in " + LogFilename
+		}
+		embed.Footer = &discordwebhook.EmbedFooter{Text: footer}
+		files = []discordwebhook.Attachment{{Name: LogFilename, Data: []byte(r.Log())}}
+	}
+	return discordwebhook.DiscordPayload{Embeds: []discordwebhook.DiscordEmbed{embed}, Files: files}
+}
+
+// description résume les totaux et liste les paquets en échec. complete est
+// faux si des paquets ont été omis
+func (r *Report) description(failed []*Package) (text string, complete bool) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%d** passed, **%d** failed, **%d** skipped in %s (%s)",
+		r.Passed, r.Failed, r.Skipped, plural(len(r.Packages), "package"), r.Elapsed.Round(time.Millisecond))
+	if len(failed) > 0 {
+		b.WriteString("\n")
+	}
+
+	limit := discordwebhook.MaxEmbedDescriptionLength / 2
+	for i, pkg := range failed {
+		line := fmt.Sprintf("\n❌ `%s` %d failed, %d passed", pkg.Name, pkg.Failed, pkg.Passed)
+		if pkg.Failed == 0 {
+			line = fmt.Sprintf("\n❌ `%s` %s", pkg.Name, firstLine(pkg.Output))
+		}
+		if len([]rune(b.String()+line)) > limit {
+			fmt.Fprintf(&b, "\n… and %s", plural(len(failed)-i, "more package"))
+			return b.String(), false
+		}
+		b.WriteString(line)
+	}
+	return b.String(), true
+}
+
+// fields retourne un champ par test en échec, puis un par paquet en échec
+// sans test en échec, la valeur étant la sortie complète
+func (r *Report) fields(failed []*Package) []discordwebhook.EmbedField {
+	var fields []discordwebhook.EmbedField
+	for _, pkg := range failed {
+		for _, test := range pkg.Failures {
+			fields = append(fields, discordwebhook.EmbedField{
+				Name:  discordwebhook.Truncate(test.Name+" · "+shortName(pkg.Name), discordwebhook.MaxEmbedFieldNameLength),
+				Value: test.Output,
+			})
+		}
+		if pkg.Output != "" && len(pkg.Failures) == 0 {
+			fields = append(fields, discordwebhook.EmbedField{
+				Name:  discordwebhook.Truncate(pkg.Name, discordwebhook.MaxEmbedFieldNameLength),
+				Value: pkg.Output,
+			})
+		}
+	}
+	if r.Output != "" && !r.OK() {
+		fields = append(fields, discordwebhook.EmbedField{Name: "Output", Value: r.Output})
+	}
+	return fields
+}
+
+// minExcerpt est la longueur minimale d'un extrait de sortie lorsque de
+// nombreux tests ont échoué
+const minExcerpt = 256
+
+// excerpt place la fin de output dans un bloc de code d'au plus limit
+// caractères et indique si elle a été tronquée
+func excerpt(output string, limit int) (string, bool) {
+	if strings.TrimSpace(output) == "" {
+		return "*no output*", false
+	}
+	return discordwebhook.TailCodeBlock(output, limit)
+}
+
+// shortName retourne le dernier élément du chemin d'import
+func shortName(pkg string) string {
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		return pkg[i+1:]
+	}
+	return pkg
+}
+
+// firstLine retourne la première ligne de s, hors en-têtes "# paquet" des
+// erreurs de compilation, ou "failed" si s est vide
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			return discordwebhook.Truncate(line, 200)
+		}
+	}
+	return "failed"
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, word)
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
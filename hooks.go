@@ -0,0 +1,140 @@
+package discordwebhook
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Hooks reçoit les événements du cycle de vie des requêtes HTTP, ex: pour
+// alimenter des métriques ou des logs. Les fonctions nil sont ignorées et
+// les hooks sont appelés de façon synchrone, ils doivent donc rester rapides
+type Hooks struct {
+	// OnRequest est appelé avant chaque tentative
+	OnRequest func(info RequestInfo)
+	// OnRateLimit est appelé à chaque réponse 429, avant l'attente
+	OnRateLimit func(info RateLimitInfo)
+	// OnRetry est appelé après l'attente, avant une nouvelle tentative
+	OnRetry func(info RetryInfo)
+	// OnResult est appelé une fois la requête terminée, avec ou sans erreur
+	OnResult func(info ResultInfo)
+}
+
+// RequestInfo décrit une requête adressée au webhook
+type RequestInfo struct {
+	// WebhookID identifie le webhook, le jeton n'est jamais exposé
+	WebhookID string
+	Method    string
+	// Size est la taille du corps de la requête en octets
+	Size int
+	// Attachments est le nombre de fichiers joints
+	Attachments int
+	// Attempt est le numéro de la tentative, à partir de 1
+	Attempt int
+}
+
+// RateLimit contient les informations de limite de débit retournées par
+// Discord dans les en-têtes X-RateLimit-*
+type RateLimit struct {
+	Bucket     string
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+	Global     bool
+	// Scope vaut "user", "global" ou "shared" pour une réponse 429
+	Scope string
+}
+
+// RateLimitInfo décrit une réponse 429
+type RateLimitInfo struct {
+	RequestInfo
+	RateLimit RateLimit
+	// Wait est la durée d'attente avant la prochaine tentative
+	Wait time.Duration
+}
+
+// RetryInfo décrit une nouvelle tentative
+type RetryInfo struct {
+	RequestInfo
+	// Wait est la durée attendue depuis la tentative précédente
+	Wait time.Duration
+	// StatusCode est le statut de la tentative précédente
+	StatusCode int
+}
+
+// ResultInfo décrit le résultat final d'une requête
+type ResultInfo struct {
+	RequestInfo
+	// StatusCode est le statut de la dernière réponse, 0 sans réponse
+	StatusCode int
+	RateLimit  RateLimit
+	// Latency est la durée totale de la requête, attentes comprises
+	Latency time.Duration
+	Err     error
+}
+
+// WithHooks ajoute des hooks au client. Plusieurs hooks peuvent être ajoutés,
+// ils sont alors appelés dans l'ordre
+func WithHooks(hooks Hooks) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.client.hooks = append(cfg.client.hooks, hooks)
+	})
+}
+
+// hookList appelle successivement les hooks du client
+type hookList []Hooks
+
+func (l hookList) request(info RequestInfo) {
+	for _, h := range l {
+		if h.OnRequest != nil {
+			h.OnRequest(info)
+		}
+	}
+}
+
+func (l hookList) rateLimit(info RateLimitInfo) {
+	for _, h := range l {
+		if h.OnRateLimit != nil {
+			h.OnRateLimit(info)
+		}
+	}
+}
+
+func (l hookList) retry(info RetryInfo) {
+	for _, h := range l {
+		if h.OnRetry != nil {
+			h.OnRetry(info)
+		}
+	}
+}
+
+func (l hookList) result(info ResultInfo) {
+	for _, h := range l {
+		if h.OnResult != nil {
+			h.OnResult(info)
+		}
+	}
+}
+
+// parseRateLimit lit les en-têtes de limite de débit d'une réponse
+func parseRateLimit(header http.Header) RateLimit {
+	limit, _ := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	remaining, _ := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	return RateLimit{
+		Bucket:     header.Get("X-RateLimit-Bucket"),
+		Limit:      limit,
+		Remaining:  remaining,
+		ResetAfter: parseSeconds(header.Get("X-RateLimit-Reset-After")),
+		Global:     header.Get("X-RateLimit-Global") == "true",
+		Scope:      header.Get("X-RateLimit-Scope"),
+	}
+}
+
+// parseSeconds convertit une durée en secondes décimales, 0 si invalide
+func parseSeconds(s string) time.Duration {
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
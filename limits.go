@@ -0,0 +1,85 @@
+package discordwebhook
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Limites imposées par Discord sur le contenu d'un message
+const (
+	MaxContentLength          = 2000
+	MaxUsernameLength         = 80
+	MaxEmbedsPerMessage       = 10
+	MaxFilesPerMessage        = 10
+	MaxEmbedTotalLength       = 6000
+	MaxEmbedTitleLength       = 256
+	MaxEmbedDescriptionLength = 4096
+	MaxEmbedFields            = 25
+	MaxEmbedFieldNameLength   = 256
+	MaxEmbedFieldValueLength  = 1024
+	MaxEmbedFooterLength      = 2048
+	MaxEmbedAuthorNameLength  = 256
+)
+
+// Length retourne le nombre de caractères de l'embed tel que compté par Discord
+// pour la limite de MaxEmbedTotalLength
+func (e DiscordEmbed) Length() int {
+	n := runeCount(e.Title) + runeCount(e.Description)
+	if e.Author != nil {
+		n += runeCount(e.Author.Name)
+	}
+	if e.Footer != nil {
+		n += runeCount(e.Footer.Text)
+	}
+	for _, field := range e.Fields {
+		n += runeCount(field.Name) + runeCount(field.Value)
+	}
+	return n
+}
+
+// embedsLength retourne la longueur cumulée d'une liste d'embeds
+func embedsLength(embeds []DiscordEmbed) int {
+	n := 0
+	for _, embed := range embeds {
+		n += embed.Length()
+	}
+	return n
+}
+
+func runeCount(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+// Truncate tronque s à max caractères en terminant par une ellipse, comme Fit
+func Truncate(s string, max int) string {
+	truncated, _ := truncate(s, max)
+	return truncated
+}
+
+// TailCodeBlock place la fin de text dans un bloc de code d'au plus max
+// caractères, délimiteurs compris, pour afficher la fin d'une sortie de
+// commande. Le texte tronqué commence si possible au début d'une ligne et les
+// ``` qu'il contient sont neutralisés. truncated indique si le début a été
+// omis. Le plus petit bloc, réduit à l'ellipse, fait 9 caractères : il est
+// retourné lorsque max est inférieur
+func TailCodeBlock(text string, max int) (block string, truncated bool) {
+	text = strings.ReplaceAll(text, "```", "`\u200b``")
+	runes := []rune(text)
+	budget := max - len("```\n\n```")
+	if len(runes) <= budget || len(runes) == 0 {
+		return "```\n" + text + "\n```", false
+	}
+
+	keep := budget - 1
+	if keep < 0 {
+		keep = 0
+	}
+	runes = runes[len(runes)-keep:]
+	for i := 0; i < len(runes)-1; i++ {
+		if runes[i] == '\n' {
+			runes = runes[i+1:]
+			break
+		}
+	}
+	return "```\n" + ellipsis + string(runes) + "\n```", true
+}
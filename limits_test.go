@@ -0,0 +1,36 @@
+package discordwebhook_test
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	discordwebhook "github.com/peepsii/discord-webhook-go"
+)
+
+func TestTailCodeBlock(t *testing.T) {
+	tests := []struct {
+		text      string
+		max       int
+		want      string
+		truncated bool
+	}{
+		{"hello\nworld", 100, "```\nhello\nworld\n```", false},
+		{"hello\nworld", 19, "```\nhello\nworld\n```", false},
+		{"hello\nworld", 18, "```\n…world\n```", true},
+		{"hello\nworld", 11, "```\n…ld\n```", true},
+		{"hello\nworld", 9, "```\n…\n```", true},
+		{"hello\nworld", 0, "```\n…\n```", true},
+		{"hello\nworld", -1, "```\n…\n```", true},
+		{"", 0, "```\n\n```", false},
+		{"a ``` b", 100, "```\na `\u200b`` b\n```", false},
+	}
+	for _, tt := range tests {
+		got, truncated := discordwebhook.TailCodeBlock(tt.text, tt.max)
+		if got != tt.want || truncated != tt.truncated {
+			t.Errorf("TailCodeBlock(%q, %d) = %q, %v, want %q, %v", tt.text, tt.max, got, truncated, tt.want, tt.truncated)
+		}
+		if n := utf8.RuneCountInString(got); tt.max >= 9 && n > tt.max {
+			t.Errorf("TailCodeBlock(%q, %d) has %d characters", tt.text, tt.max, n)
+		}
+	}
+}
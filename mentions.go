@@ -0,0 +1,104 @@
+package discordwebhook
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MaxAllowedMentionIDs est le nombre maximal d'utilisateurs ou de rôles
+// autorisés explicitement dans AllowedMentions
+const MaxAllowedMentionIDs = 100
+
+// MentionType représente une catégorie de mentions résolue par Discord
+type MentionType string
+
+const (
+	MentionUsers MentionType = "users"
+	MentionRoles MentionType = "roles"
+	// MentionEveryone couvre @everyone et @here
+	MentionEveryone MentionType = "everyone"
+)
+
+// AllowedMentions restreint les mentions du message qui notifient réellement
+// leurs destinataires. Une liste Parse vide ne notifie personne en dehors des
+// utilisateurs et rôles autorisés explicitement.
+type AllowedMentions struct {
+	Parse       []MentionType `json:"parse"`
+	Users       []string      `json:"users,omitempty"`
+	Roles       []string      `json:"roles,omitempty"`
+	RepliedUser bool          `json:"replied_user,omitempty"`
+}
+
+// DefaultAllowedMentions retourne les mentions appliquées par défaut par le
+// client : seuls les utilisateurs sont notifiés, jamais @everyone, @here ni
+// les rôles
+func DefaultAllowedMentions() *AllowedMentions {
+	return &AllowedMentions{Parse: []MentionType{MentionUsers}}
+}
+
+// NoMentions retourne des mentions qui ne notifient personne
+func NoMentions() *AllowedMentions {
+	return &AllowedMentions{Parse: []MentionType{}}
+}
+
+// AllMentions retourne des mentions qui notifient tout le monde, comme le fait
+// Discord lorsque allowed_mentions est absent
+func AllMentions() *AllowedMentions {
+	return &AllowedMentions{Parse: []MentionType{MentionUsers, MentionRoles, MentionEveryone}}
+}
+
+// AllowUsers autorise la notification des utilisateurs ids
+func (m *AllowedMentions) AllowUsers(ids ...string) *AllowedMentions {
+	m.Users = append(m.Users, ids...)
+	return m
+}
+
+// AllowRoles autorise la notification des membres des rôles ids
+func (m *AllowedMentions) AllowRoles(ids ...string) *AllowedMentions {
+	m.Roles = append(m.Roles, ids...)
+	return m
+}
+
+// MarshalJSON sérialise toujours parse sous forme de liste, une valeur null
+// laissant Discord appliquer ses propres règles
+func (m AllowedMentions) MarshalJSON() ([]byte, error) {
+	type allowedMentions AllowedMentions
+	if m.Parse == nil {
+		m.Parse = []MentionType{}
+	}
+	return json.Marshal(allowedMentions(m))
+}
+
+func (m AllowedMentions) validate(v *validator, path string) {
+	for i, parse := range m.Parse {
+		switch parse {
+		case MentionUsers:
+			if len(m.Users) > 0 {
+				v.addf(joinPath(path, "parse"), "%q cannot be combined with an explicit users list", parse)
+			}
+		case MentionRoles:
+			if len(m.Roles) > 0 {
+				v.addf(joinPath(path, "parse"), "%q cannot be combined with an explicit roles list", parse)
+			}
+		case MentionEveryone:
+		default:
+			v.addf(joinPath(path, fmt.Sprintf("parse[%d]", i)), "unknown mention type %q", parse)
+		}
+	}
+	if len(m.Users) > MaxAllowedMentionIDs {
+		v.addf(joinPath(path, "users"), "%d users exceed the limit of %d", len(m.Users), MaxAllowedMentionIDs)
+	}
+	if len(m.Roles) > MaxAllowedMentionIDs {
+		v.addf(joinPath(path, "roles"), "%d roles exceed the limit of %d", len(m.Roles), MaxAllowedMentionIDs)
+	}
+}
+
+// MentionUser retourne la mention de l'utilisateur id à insérer dans le texte
+func MentionUser(id string) string {
+	return "<@" + id + ">"
+}
+
+// MentionRole retourne la mention du rôle id à insérer dans le texte
+func MentionRole(id string) string {
+	return "<@&" + id + ">"
+}
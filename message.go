@@ -0,0 +1,58 @@
+package discordwebhook
+
+import "time"
+
+// Message représente un message renvoyé par Discord
+type Message struct {
+	ID              string              `json:"id"`
+	ChannelID       string              `json:"channel_id"`
+	WebhookID       string              `json:"webhook_id,omitempty"`
+	Author          *MessageAuthor      `json:"author,omitempty"`
+	Content         string              `json:"content"`
+	Timestamp       time.Time           `json:"timestamp"`
+	EditedTimestamp *time.Time          `json:"edited_timestamp,omitempty"`
+	TTS             bool                `json:"tts"`
+	Embeds          []DiscordEmbed      `json:"embeds"`
+	Attachments     []MessageAttachment `json:"attachments"`
+	Components      Components          `json:"components,omitempty"`
+	Flags           MessageFlags        `json:"flags"`
+	Poll            *Poll               `json:"poll,omitempty"`
+}
+
+// MessageAuthor représente l'auteur d'un message, le webhook lui-même pour les
+// messages envoyés par webhook
+type MessageAuthor struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Avatar   string `json:"avatar,omitempty"`
+	Bot      bool   `json:"bot,omitempty"`
+}
+
+// MessageAttachment représente un fichier joint à un message envoyé
+type MessageAttachment struct {
+	ID          string `json:"id"`
+	Filename    string `json:"filename"`
+	Size        int    `json:"size"`
+	URL         string `json:"url"`
+	ProxyURL    string `json:"proxy_url"`
+	ContentType string `json:"content_type,omitempty"`
+}
+
+// Webhook représente un webhook tel que retourné par Discord, sans son jeton
+type Webhook struct {
+	ID            string `json:"id"`
+	Type          int    `json:"type"`
+	GuildID       string `json:"guild_id,omitempty"`
+	ChannelID     string `json:"channel_id"`
+	Name          string `json:"name"`
+	Avatar        string `json:"avatar,omitempty"`
+	ApplicationID string `json:"application_id,omitempty"`
+}
+
+// AvatarURL retourne l'URL de l'avatar du webhook, vide s'il n'en a pas
+func (w Webhook) AvatarURL() string {
+	if w.Avatar == "" {
+		return ""
+	}
+	return "https://cdn.discordapp.com/avatars/" + w.ID + "/" + w.Avatar + ".png"
+}
@@ -0,0 +1,284 @@
+package discordwebhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLatencyBuckets sont les bornes en secondes de l'histogramme de
+// latence des requêtes
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Metrics collecte les statistiques d'envoi par webhook. Il s'enregistre sur
+// un client avec WithMetrics, peut être partagé entre plusieurs clients et
+// s'exporte via expvar (Metrics implémente expvar.Var) ou au format texte de
+// Prometheus avec Handler
+type Metrics struct {
+	mu       sync.Mutex
+	buckets  []float64
+	webhooks map[string]*WebhookStats
+}
+
+// WebhookStats sont les statistiques d'un webhook
+type WebhookStats struct {
+	// Messages, Bytes et Attachments comptent les messages envoyés avec succès
+	Messages    int64 `json:"messages"`
+	Bytes       int64 `json:"bytes"`
+	Attachments int64 `json:"attachments"`
+	// Retries compte les nouvelles tentatives après une limite de débit
+	Retries int64 `json:"retries"`
+	// RateLimits compte les réponses 429 par portée ("user", "global",
+	// "shared" ou "unknown")
+	RateLimits map[string]int64 `json:"rate_limits"`
+	// Failures compte les échecs par code d'erreur Discord, "0" pour une
+	// erreur HTTP sans code, "rate_limited" lorsque WithMaxRetries est épuisé
+	// et "transport" pour une erreur réseau
+	Failures map[string]int64 `json:"failures"`
+	Latency  Histogram        `json:"latency"`
+}
+
+// Histogram répartit des durées en secondes. Counts[i] compte les valeurs
+// inférieures ou égales à Buckets[i], non cumulées ; la dernière case de
+// Counts compte les valeurs au-delà de la dernière borne
+type Histogram struct {
+	Buckets []float64 `json:"buckets"`
+	Counts  []int64   `json:"counts"`
+	Sum     float64   `json:"sum"`
+	Count   int64     `json:"count"`
+}
+
+func (h *Histogram) observe(value float64) {
+	i := sort.SearchFloat64s(h.Buckets, value)
+	h.Counts[i]++
+	h.Sum += value
+	h.Count++
+}
+
+// NewMetrics crée un collecteur utilisant les bornes de latence buckets en
+// secondes, DefaultLatencyBuckets si aucune n'est fournie
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Metrics{buckets: buckets, webhooks: make(map[string]*WebhookStats)}
+}
+
+// WithMetrics enregistre les statistiques d'envoi du client dans m
+func WithMetrics(m *Metrics) Option {
+	return WithHooks(m.Hooks())
+}
+
+// Hooks retourne les hooks alimentant le collecteur
+func (m *Metrics) Hooks() Hooks {
+	return Hooks{
+		OnRateLimit: m.rateLimit,
+		OnRetry:     m.retry,
+		OnResult:    m.result,
+	}
+}
+
+// stats retourne les statistiques du webhook id, m.mu doit être verrouillé
+func (m *Metrics) stats(id string) *WebhookStats {
+	s, ok := m.webhooks[id]
+	if !ok {
+		s = &WebhookStats{
+			RateLimits: make(map[string]int64),
+			Failures:   make(map[string]int64),
+			Latency:    Histogram{Buckets: m.buckets, Counts: make([]int64, len(m.buckets)+1)},
+		}
+		m.webhooks[id] = s
+	}
+	return s
+}
+
+func (m *Metrics) rateLimit(info RateLimitInfo) {
+	scope := info.RateLimit.Scope
+	if scope == "" {
+		scope = "unknown"
+	}
+	m.mu.Lock()
+	m.stats(info.WebhookID).RateLimits[scope]++
+	m.mu.Unlock()
+}
+
+func (m *Metrics) retry(info RetryInfo) {
+	m.mu.Lock()
+	m.stats(info.WebhookID).Retries++
+	m.mu.Unlock()
+}
+
+func (m *Metrics) result(info ResultInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.stats(info.WebhookID)
+	s.Latency.observe(info.Latency.Seconds())
+
+	if info.Err != nil {
+		code := "transport"
+		var apiErr *APIError
+		var rateLimitErr *RateLimitError
+		switch {
+		case errors.As(info.Err, &apiErr):
+			code = strconv.Itoa(apiErr.Code)
+		case errors.As(info.Err, &rateLimitErr):
+			code = "rate_limited"
+		}
+		s.Failures[code]++
+		return
+	}
+	if info.Method == http.MethodPost {
+		s.Messages++
+		s.Bytes += int64(info.Size)
+		s.Attachments += int64(info.Attachments)
+	}
+}
+
+// Snapshot retourne une copie des statistiques, indexées par ID de webhook
+func (m *Metrics) Snapshot() map[string]WebhookStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(map[string]WebhookStats, len(m.webhooks))
+	for id, s := range m.webhooks {
+		c := *s
+		c.RateLimits = copyCounts(s.RateLimits)
+		c.Failures = copyCounts(s.Failures)
+		c.Latency.Counts = append([]int64(nil), s.Latency.Counts...)
+		snapshot[id] = c
+	}
+	return snapshot
+}
+
+func copyCounts(counts map[string]int64) map[string]int64 {
+	c := make(map[string]int64, len(counts))
+	for k, v := range counts {
+		c[k] = v
+	}
+	return c
+}
+
+// String retourne les statistiques au format JSON, pour expvar.Publish
+func (m *Metrics) String() string {
+	data, err := json.Marshal(m.Snapshot())
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// Handler expose les statistiques au format texte de Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.WritePrometheus(w)
+	})
+}
+
+// WritePrometheus écrit les statistiques au format texte de Prometheus
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	snapshot := m.Snapshot()
+	ids := make([]string, 0, len(snapshot))
+	for id := range snapshot {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	p := &promWriter{w: w}
+	counters := []struct {
+		name, help string
+		value      func(s WebhookStats) int64
+	}{
+		{"messages_total", "Messages sent successfully.", func(s WebhookStats) int64 { return s.Messages }},
+		{"bytes_total", "Request body bytes of messages sent successfully.", func(s WebhookStats) int64 { return s.Bytes }},
+		{"attachments_total", "Files attached to messages sent successfully.", func(s WebhookStats) int64 { return s.Attachments }},
+		{"retries_total", "Attempts retried after a rate limit.", func(s WebhookStats) int64 { return s.Retries }},
+	}
+	for _, counter := range counters {
+		p.header(counter.name, counter.help, "counter")
+		for _, id := range ids {
+			p.sample(counter.name, labels("webhook_id", id), float64(counter.value(snapshot[id])))
+		}
+	}
+
+	p.header("rate_limits_total", "Rate limited responses by scope.", "counter")
+	for _, id := range ids {
+		p.counts("rate_limits_total", id, "scope", snapshot[id].RateLimits)
+	}
+	p.header("failures_total", "Failed requests by Discord error code.", "counter")
+	for _, id := range ids {
+		p.counts("failures_total", id, "code", snapshot[id].Failures)
+	}
+
+	p.header("request_duration_seconds", "Request latency including rate limit waits.", "histogram")
+	for _, id := range ids {
+		h := snapshot[id].Latency
+		var cumulative int64
+		for i, bound := range h.Buckets {
+			cumulative += h.Counts[i]
+			le := strconv.FormatFloat(bound, 'g', -1, 64)
+			p.sample("request_duration_seconds_bucket", labels("webhook_id", id, "le", le), float64(cumulative))
+		}
+		p.sample("request_duration_seconds_bucket", labels("webhook_id", id, "le", "+Inf"), float64(h.Count))
+		p.sample("request_duration_seconds_sum", labels("webhook_id", id), h.Sum)
+		p.sample("request_duration_seconds_count", labels("webhook_id", id), float64(h.Count))
+	}
+
+	return p.err
+}
+
+// metricsPrefix préfixe le nom des métriques Prometheus
+const metricsPrefix = "discord_webhook_"
+
+// promWriter écrit le format texte de Prometheus en conservant la première
+// erreur d'écriture
+type promWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (p *promWriter) printf(format string, args ...any) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, format, args...)
+	}
+}
+
+func (p *promWriter) header(name, help, kind string) {
+	p.printf("# HELP %s%s %s\n# TYPE %s%s %s\n", metricsPrefix, name, help, metricsPrefix, name, kind)
+}
+
+func (p *promWriter) sample(name, labels string, value float64) {
+	p.printf("%s%s{%s} %s\n", metricsPrefix, name, labels, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// counts écrit un compteur par valeur du label key, triées
+func (p *promWriter) counts(name, id, key string, counts map[string]int64) {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p.sample(name, labels("webhook_id", id, key, k), float64(counts[k]))
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels formate des paires clé/valeur en labels Prometheus
+func labels(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, pairs[i]+`="`+labelEscaper.Replace(pairs[i+1])+`"`)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,179 @@
+package discordwebhook_test
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	discordwebhook "github.com/peepsii/discord-webhook-go"
+	"github.com/peepsii/discord-webhook-go/discordwebhooktest"
+)
+
+func TestMetrics(t *testing.T) {
+	payload := discordwebhook.DiscordPayload{
+		Content: "build",
+		Files: []discordwebhook.Attachment{
+			{Name: "build.log", Data: []byte("ok")},
+			{Name: "report.txt", Data: []byte("done")},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		responses   []discordwebhooktest.Response
+		options     []discordwebhook.Option
+		send        func(client *discordwebhook.Client) error
+		messages    int64
+		attachments int64
+		retries     int64
+		rateLimits  map[string]int64
+		failures    map[string]int64
+		requests    int64
+	}{
+		{
+			name:     "message",
+			send:     func(client *discordwebhook.Client) error { return client.SendMessage("hello") },
+			messages: 1,
+			requests: 1,
+		},
+		{
+			name:        "attachments",
+			send:        func(client *discordwebhook.Client) error { return client.SendCustomPayload(payload) },
+			messages:    1,
+			attachments: 2,
+			requests:    1,
+		},
+		{
+			name: "edit",
+			send: func(client *discordwebhook.Client) error {
+				message, err := client.SendAndWait(discordwebhook.DiscordPayload{Content: "Deploying"})
+				if err != nil {
+					return err
+				}
+				_, err = client.EditMessage(message.ID, discordwebhook.DiscordPayload{Content: "Deployed"})
+				return err
+			},
+			messages: 1,
+			requests: 2,
+		},
+		{
+			name:       "retried rate limit",
+			responses:  []discordwebhooktest.Response{discordwebhooktest.RateLimited(10*time.Millisecond, false)},
+			send:       func(client *discordwebhook.Client) error { return client.SendMessage("hello") },
+			messages:   1,
+			retries:    1,
+			rateLimits: map[string]int64{"user": 1},
+			requests:   1,
+		},
+		{
+			name:       "exhausted retries",
+			responses:  []discordwebhooktest.Response{discordwebhooktest.RateLimited(10*time.Millisecond, true)},
+			options:    []discordwebhook.Option{discordwebhook.WithMaxRetries(0)},
+			send:       func(client *discordwebhook.Client) error { return client.SendMessage("hello") },
+			rateLimits: map[string]int64{"global": 1},
+			failures:   map[string]int64{"rate_limited": 1},
+			requests:   1,
+		},
+		{
+			name:      "server error",
+			responses: []discordwebhooktest.Response{discordwebhooktest.ServerError(http.StatusBadGateway)},
+			send:      func(client *discordwebhook.Client) error { return client.SendMessage("hello") },
+			failures:  map[string]int64{"0": 1},
+			requests:  1,
+		},
+		{
+			name: "unknown message",
+			send: func(client *discordwebhook.Client) error {
+				return client.DeleteMessage("1")
+			},
+			failures: map[string]int64{"10008": 1},
+			requests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newServer(t)
+			server.Enqueue(tt.responses...)
+			metrics := discordwebhook.NewMetrics()
+			client := server.Client(append(tt.options, discordwebhook.WithMetrics(metrics))...)
+			if err := tt.send(client); err != nil && tt.failures == nil {
+				t.Fatalf("send error = %v", err)
+			}
+
+			stats, ok := metrics.Snapshot()[discordwebhooktest.ServerWebhookID]
+			if !ok {
+				t.Fatalf("no statistics for webhook %s", discordwebhooktest.ServerWebhookID)
+			}
+			if stats.Messages != tt.messages || stats.Attachments != tt.attachments || stats.Retries != tt.retries {
+				t.Errorf("messages, attachments, retries = %d, %d, %d, want %d, %d, %d",
+					stats.Messages, stats.Attachments, stats.Retries, tt.messages, tt.attachments, tt.retries)
+			}
+			if (stats.Messages > 0) != (stats.Bytes > 0) {
+				t.Errorf("Bytes = %d for %d messages", stats.Bytes, stats.Messages)
+			}
+			if !equalCounts(stats.RateLimits, tt.rateLimits) {
+				t.Errorf("RateLimits = %v, want %v", stats.RateLimits, tt.rateLimits)
+			}
+			if !equalCounts(stats.Failures, tt.failures) {
+				t.Errorf("Failures = %v, want %v", stats.Failures, tt.failures)
+			}
+			if stats.Latency.Count != tt.requests {
+				t.Errorf("Latency.Count = %d, want %d", stats.Latency.Count, tt.requests)
+			}
+		})
+	}
+}
+
+// equalCounts compare deux compteurs, nil valant une table vide
+func equalCounts(got, want map[string]int64) bool {
+	if len(got) == 0 && len(want) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(got, want)
+}
+
+func TestMetricsTransportFailure(t *testing.T) {
+	server := discordwebhooktest.NewServer()
+	server.Close()
+
+	metrics := discordwebhook.NewMetrics()
+	if err := server.Client(discordwebhook.WithMetrics(metrics)).SendMessage("hello"); err == nil {
+		t.Fatal("SendMessage() error = nil on a closed server")
+	}
+	stats := metrics.Snapshot()[discordwebhooktest.ServerWebhookID]
+	if !reflect.DeepEqual(stats.Failures, map[string]int64{"transport": 1}) {
+		t.Errorf("Failures = %v, want one transport failure", stats.Failures)
+	}
+}
+
+func TestMetricsPrometheus(t *testing.T) {
+	server := newServer(t)
+	metrics := discordwebhook.NewMetrics(1, 0.5)
+	client := server.Client(discordwebhook.WithMetrics(metrics))
+	for i := 0; i < 2; i++ {
+		if err := client.SendMessage("hello"); err != nil {
+			t.Fatalf("SendMessage() error = %v", err)
+		}
+	}
+
+	var out strings.Builder
+	if err := metrics.WritePrometheus(&out); err != nil {
+		t.Fatalf("WritePrometheus() error = %v", err)
+	}
+	id := `webhook_id="` + discordwebhooktest.ServerWebhookID + `"`
+	for _, want := range []string{
+		"# TYPE discord_webhook_messages_total counter\n",
+		"discord_webhook_messages_total{" + id + "} 2\n",
+		"discord_webhook_retries_total{" + id + "} 0\n",
+		"# TYPE discord_webhook_request_duration_seconds histogram\n",
+		"discord_webhook_request_duration_seconds_bucket{" + id + `,le="0.5"} 2` + "\n",
+		"discord_webhook_request_duration_seconds_bucket{" + id + `,le="+Inf"} 2` + "\n",
+		"discord_webhook_request_duration_seconds_count{" + id + "} 2\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output lacks %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package discordwebhook
+
+import (
+	"context"
+	"net/http"
+)
+
+// Request décrit une requête adressée au webhook, telle que vue par les
+// middlewares
+type Request struct {
+	// Method est la méthode HTTP : POST pour un envoi, PATCH pour une
+	// modification, DELETE pour une suppression et GET pour une lecture
+	Method string
+	// MessageID identifie le message ciblé, vide pour un envoi
+	MessageID string
+	// Payload est le message à envoyer, après application des options du
+	// client et découpage par AutoFit
+	Payload DiscordPayload
+	// Wait demande à Discord de retourner le message créé
+	Wait bool
+}
+
+// Handler traite une requête adressée au webhook. Le message retourné est nil
+// lorsque Discord n'en retourne pas, par exemple pour un envoi sans Wait
+type Handler interface {
+	Handle(ctx context.Context, req *Request) (*Message, error)
+}
+
+// HandlerFunc permet d'utiliser une fonction comme Handler
+type HandlerFunc func(ctx context.Context, req *Request) (*Message, error)
+
+// Handle appelle f(ctx, req)
+func (f HandlerFunc) Handle(ctx context.Context, req *Request) (*Message, error) {
+	return f(ctx, req)
+}
+
+// Middleware enveloppe le traitement des requêtes, ex: journal d'audit ou
+// injection de pannes. Il peut modifier la requête, l'intercepter sans
+// appeler next ou agir sur le résultat
+type Middleware func(next Handler) Handler
+
+// RoundTripperFunc permet d'utiliser une fonction comme http.RoundTripper
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip appelle f(req)
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// TransportMiddleware enveloppe le transport HTTP, ex: ajout d'en-têtes ou
+// signature des requêtes pour une passerelle de sortie. Il voit chaque
+// tentative, y compris les nouvelles tentatives après une limite de débit
+type TransportMiddleware func(next http.RoundTripper) http.RoundTripper
+
+// WithMiddleware ajoute des middlewares autour du traitement des requêtes. Le
+// premier middleware ajouté est le plus externe : il reçoit la requête en
+// premier et le résultat en dernier. Les middlewares s'exécutent une fois par
+// message, après l'application des options du client et le découpage
+// AutoFit, avant la validation du payload
+func WithMiddleware(middlewares ...Middleware) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.middlewares = append(cfg.middlewares, middlewares...)
+	})
+}
+
+// WithTransportMiddleware ajoute des middlewares autour du transport HTTP,
+// une fois les options de proxy et TLS appliquées. Le premier middleware
+// ajouté est le plus externe
+func WithTransportMiddleware(middlewares ...TransportMiddleware) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.transportMiddlewares = append(cfg.transportMiddlewares, middlewares...)
+	})
+}
+
+// chain enveloppe handler dans les middlewares, le premier étant le plus externe
+func (cfg *clientConfig) chain(handler Handler) Handler {
+	for i := len(cfg.middlewares) - 1; i >= 0; i-- {
+		handler = cfg.middlewares[i](handler)
+	}
+	return handler
+}
+
+// chainTransport enveloppe transport dans les middlewares de transport
+func (cfg *clientConfig) chainTransport(transport http.RoundTripper) http.RoundTripper {
+	if len(cfg.transportMiddlewares) == 0 {
+		return transport
+	}
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	for i := len(cfg.transportMiddlewares) - 1; i >= 0; i-- {
+		transport = cfg.transportMiddlewares[i](transport)
+	}
+	return transport
+}
@@ -0,0 +1,215 @@
+package discordwebhook
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidNotificationURL est retournée lorsqu'une URL discord:// ne peut pas
+// être analysée. Le message d'erreur ne reprend jamais l'URL fournie
+var ErrInvalidNotificationURL = errors.New("invalid notification URL")
+
+// ParseNotificationURL crée un client configuré à partir d'une URL de
+// notification, au format utilisé par shoutrrr et Apprise :
+//
+//	discord://token@id?username=Bot&avatar=https://...&thread_id=123
+//	discord://id/token?tts=yes&silent=yes&color=0x3498db
+//	discord://botname@id/token
+//
+// Les paramètres reconnus sont username (ou botname), avatar_url (ou avatar),
+// thread_id, tts, silent et color, les autres sont ignorés. Une URL donnant
+// deux alias d'un même paramètre est refusée. Les options fournies sont
+// appliquées avant celles de l'URL
+func ParseNotificationURL(raw string, options ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed URL", ErrInvalidNotificationURL)
+	}
+	if u.Scheme != "discord" {
+		return nil, fmt.Errorf("%w: unsupported scheme %q", ErrInvalidNotificationURL, u.Scheme)
+	}
+
+	// shoutrrr place le jeton dans l'utilisateur, Apprise dans le chemin et
+	// éventuellement le nom du bot dans l'utilisateur
+	var botname string
+	id, token := u.Host, strings.Trim(u.Path, "/")
+	if token != "" {
+		if u.User != nil {
+			botname = u.User.Username()
+		}
+	} else if u.User != nil {
+		token = u.User.Username()
+	}
+	if strings.Contains(token, "/") {
+		return nil, fmt.Errorf("%w: unexpected path", ErrInvalidNotificationURL)
+	}
+
+	webhookURL := WebhookURL{id: id, token: token}
+	if err := webhookURL.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidNotificationURL, err)
+	}
+
+	setters, err := notificationSetters(u.Query())
+	if err != nil {
+		return nil, err
+	}
+	if botname != "" {
+		setters = append([]func(*WebhookOptions){func(o *WebhookOptions) { o.Username = botname }}, setters...)
+	}
+
+	fromURL := optionFunc(func(cfg *clientConfig) {
+		for _, set := range setters {
+			set(&cfg.client.Options)
+		}
+	})
+	return NewClient(webhookURL.endpoint(), append(options[:len(options):len(options)], fromURL)...), nil
+}
+
+// notificationParams associe les paramètres reconnus, en minuscules, à
+// l'option qu'ils définissent
+var notificationParams = map[string]string{
+	"username":   "username",
+	"botname":    "username",
+	"avatar_url": "avatar",
+	"avatar":     "avatar",
+	"thread_id":  "thread",
+	"thread":     "thread",
+	"tts":        "tts",
+	"silent":     "silent",
+	"color":      "color",
+}
+
+// notificationSetters traduit les paramètres d'une URL discord:// en
+// modifications des WebhookOptions du client. Deux alias d'une même option,
+// ex: username et botname, sont refusés plutôt que départagés au hasard
+func notificationSetters(query url.Values) ([]func(*WebhookOptions), error) {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	params := make(map[string]string)
+	sources := make(map[string]string)
+	for _, key := range keys {
+		value := query[key][len(query[key])-1]
+		option, ok := notificationParams[strings.ToLower(key)]
+		if !ok {
+			continue
+		}
+		// Apprise utilise avatar=yes|no pour l'avatar par défaut du bot
+		if strings.EqualFold(key, "avatar") {
+			if _, err := parseBool(value); err == nil {
+				continue
+			}
+		}
+		if source, ok := sources[option]; ok {
+			return nil, fmt.Errorf("%w: %s conflicts with %s", ErrInvalidNotificationURL, key, source)
+		}
+		params[option], sources[option] = value, key
+	}
+
+	var setters []func(*WebhookOptions)
+	if value, ok := params["username"]; ok {
+		setters = append(setters, func(o *WebhookOptions) { o.Username = value })
+	}
+	if value, ok := params["avatar"]; ok {
+		setters = append(setters, func(o *WebhookOptions) { o.Avatar = value })
+	}
+	if value, ok := params["thread"]; ok {
+		setters = append(setters, func(o *WebhookOptions) { o.ThreadID = value })
+	}
+	if value, ok := params["tts"]; ok {
+		tts, err := parseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: tts: %w", ErrInvalidNotificationURL, err)
+		}
+		setters = append(setters, func(o *WebhookOptions) { o.TTS = tts })
+	}
+	if value, ok := params["silent"]; ok {
+		silent, err := parseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: silent: %w", ErrInvalidNotificationURL, err)
+		}
+		setters = append(setters, func(o *WebhookOptions) {
+			if silent {
+				o.Flags |= FlagSuppressNotifications
+			} else {
+				o.Flags &^= FlagSuppressNotifications
+			}
+		})
+	}
+	if value, ok := params["color"]; ok {
+		color, err := ParseColor(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: color: %w", ErrInvalidNotificationURL, err)
+		}
+		setters = append(setters, func(o *WebhookOptions) { o.Color = color })
+	}
+	return setters, nil
+}
+
+// NotificationURL retourne la configuration du client sous forme d'URL
+// discord:// au format shoutrrr. Contrairement à WebhookURL.String, l'URL
+// retournée contient le jeton du webhook et doit être traitée comme un secret
+func (c *Client) NotificationURL() string {
+	query := url.Values{}
+	if c.Options.Username != "" {
+		query.Set("username", c.Options.Username)
+	}
+	if c.Options.Avatar != "" {
+		query.Set("avatar", c.Options.Avatar)
+	}
+	if c.Options.ThreadID != "" {
+		query.Set("thread_id", c.Options.ThreadID)
+	}
+	if c.Options.TTS {
+		query.Set("tts", "yes")
+	}
+	if c.Options.Flags.Has(FlagSuppressNotifications) {
+		query.Set("silent", "yes")
+	}
+	if c.Options.Color != 0 {
+		query.Set("color", fmt.Sprintf("0x%06x", c.Options.Color))
+	}
+
+	u := url.URL{
+		Scheme:   "discord",
+		User:     url.User(c.WebhookURL.token),
+		Host:     c.WebhookURL.id,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "yes", "y", "true", "1", "on":
+		return true, nil
+	case "no", "n", "false", "0", "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", s)
+}
+
+// ParseColor accepte une couleur décimale ou hexadécimale (0x3498db, #3498db)
+func ParseColor(s string) (int, error) {
+	base := 10
+	lower := strings.ToLower(s)
+	switch {
+	case strings.HasPrefix(lower, "0x"):
+		s, base = s[2:], 16
+	case strings.HasPrefix(lower, "#"):
+		s, base = s[1:], 16
+	}
+
+	color, err := strconv.ParseInt(s, base, 32)
+	if err != nil || color < 0 || color > 0xffffff {
+		return 0, fmt.Errorf("invalid color %q", s)
+	}
+	return int(color), nil
+}
@@ -0,0 +1,108 @@
+package discordwebhook_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	discordwebhook "github.com/peepsii/discord-webhook-go"
+)
+
+func TestParseNotificationURL(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want discordwebhook.WebhookOptions
+	}{
+		{
+			name: "shoutrrr",
+			raw:  "discord://token@123?username=Bot&thread_id=42",
+			want: discordwebhook.WebhookOptions{Username: "Bot", ThreadID: "42"},
+		},
+		{
+			name: "apprise",
+			raw:  "discord://123/token?tts=yes&silent=yes&color=0x3498db",
+			want: discordwebhook.WebhookOptions{TTS: true, Flags: discordwebhook.FlagSuppressNotifications, Color: 0x3498db},
+		},
+		{
+			name: "apprise botname",
+			raw:  "discord://Bot@123/token?avatar_url=https://example.com/a.png",
+			want: discordwebhook.WebhookOptions{Username: "Bot", Avatar: "https://example.com/a.png"},
+		},
+		{
+			name: "apprise avatar toggle",
+			raw:  "discord://123/token?avatar=no&avatar_url=https://example.com/a.png",
+			want: discordwebhook.WebhookOptions{Avatar: "https://example.com/a.png"},
+		},
+		{
+			name: "aliases and case",
+			raw:  "discord://token@123?BotName=Bot&Thread=42&Silent=off&COLOR=%23ff0000",
+			want: discordwebhook.WebhookOptions{Username: "Bot", ThreadID: "42", Color: 0xff0000},
+		},
+		{
+			name: "unknown parameters",
+			raw:  "discord://token@123?format=markdown&overflow=split",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := discordwebhook.ParseNotificationURL(tt.raw)
+			if err != nil {
+				t.Fatalf("ParseNotificationURL() error = %v", err)
+			}
+			if client.WebhookURL.ID() != "123" {
+				t.Errorf("webhook ID = %q, want 123", client.WebhookURL.ID())
+			}
+			if !reflect.DeepEqual(client.Options, tt.want) {
+				t.Errorf("Options = %+v, want %+v", client.Options, tt.want)
+			}
+
+			// NotificationURL doit redonner la même configuration
+			reparsed, err := discordwebhook.ParseNotificationURL(client.NotificationURL())
+			if err != nil {
+				t.Fatalf("ParseNotificationURL(NotificationURL()) error = %v", err)
+			}
+			if !reflect.DeepEqual(reparsed.Options, client.Options) || reparsed.WebhookURL != client.WebhookURL {
+				t.Errorf("round trip = %+v, want %+v", reparsed.Options, client.Options)
+			}
+		})
+	}
+}
+
+func TestParseNotificationURLOverridesOptions(t *testing.T) {
+	client, err := discordwebhook.ParseNotificationURL("discord://token@123?username=FromURL",
+		discordwebhook.WebhookOptions{Username: "FromOptions", ThreadID: "42"})
+	if err != nil {
+		t.Fatalf("ParseNotificationURL() error = %v", err)
+	}
+	if client.Options.Username != "FromURL" || client.Options.ThreadID != "42" {
+		t.Errorf("Options = %+v, want the URL username and the option thread", client.Options)
+	}
+}
+
+func TestParseNotificationURLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"scheme", "https://token@123"},
+		{"missing token", "discord://123"},
+		{"numeric ID", "discord://token@abc"},
+		{"extra path", "discord://123/token/extra"},
+		{"redacted token", "discord://REDACTED@123"},
+		{"conflicting aliases", "discord://token@123?username=A&botname=B"},
+		{"conflicting case", "discord://token@123?username=A&Username=B"},
+		{"conflicting avatars", "discord://token@123?avatar=https://a&avatar_url=https://b"},
+		{"tts", "discord://token@123?tts=maybe"},
+		{"silent", "discord://token@123?silent=2"},
+		{"color", "discord://token@123?color=blue"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := discordwebhook.ParseNotificationURL(tt.raw)
+			if !errors.Is(err, discordwebhook.ErrInvalidNotificationURL) {
+				t.Fatalf("ParseNotificationURL(%q) error = %v, want ErrInvalidNotificationURL", tt.raw, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package discordwebhook
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Valeurs par défaut du client HTTP
+const (
+	DefaultTimeout   = 30 * time.Second
+	DefaultUserAgent = "DiscordWebhook-Go/1.0"
+)
+
+// Option configure un Client lors de sa création par NewClient. Les options
+// sont appliquées dans l'ordre, WebhookOptions est elle-même une Option et
+// remplace alors les WebhookOptions définies par les options précédentes
+type Option interface {
+	apply(cfg *clientConfig)
+}
+
+type optionFunc func(cfg *clientConfig)
+
+func (f optionFunc) apply(cfg *clientConfig) { f(cfg) }
+
+func (o WebhookOptions) apply(cfg *clientConfig) {
+	cfg.client.Options = o
+	if o.Proxy != nil {
+		cfg.proxy.fn = http.ProxyURL(o.Proxy)
+	}
+}
+
+// clientConfig rassemble les options nécessaires à la construction du
+// client HTTP, une fois toutes les options appliquées
+type clientConfig struct {
+	client     *Client
+	httpClient *http.Client
+	transport  http.RoundTripper
+	timeout    *time.Duration
+	proxy      proxyConfig
+	tls        tlsOptions
+	err        error
+
+	middlewares          []Middleware
+	transportMiddlewares []TransportMiddleware
+	dryRun               func(run DryRun) error
+}
+
+// fail conserve la première erreur de configuration
+func (cfg *clientConfig) fail(err error) {
+	if cfg.err == nil {
+		cfg.err = err
+	}
+}
+
+// WithHTTPClient utilise une copie de httpClient pour les requêtes. Les
+// autres options de transport s'appliquent par-dessus son Transport
+func WithHTTPClient(httpClient *http.Client) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.httpClient = httpClient
+	})
+}
+
+// WithTransport remplace le transport HTTP, http.DefaultTransport par défaut
+func WithTransport(transport http.RoundTripper) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.transport = transport
+	})
+}
+
+// WithTimeout définit la durée maximale d'une requête, DefaultTimeout par
+// défaut. Zéro désactive la limite
+func WithTimeout(timeout time.Duration) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.timeout = &timeout
+	})
+}
+
+// WithUserAgent remplace l'en-tête User-Agent envoyé à Discord
+func WithUserAgent(userAgent string) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.client.userAgent = userAgent
+	})
+}
+
+// WithBaseURL envoie les requêtes vers baseURL au lieu de https://discord.com,
+// par exemple vers une passerelle de sortie ou un serveur de test. Le chemin
+// de baseURL préfixe celui de l'API
+func WithBaseURL(baseURL string) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		u, err := url.Parse(baseURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			cfg.fail(fmt.Errorf("invalid base URL %q", baseURL))
+			return
+		}
+		u.Path = strings.TrimSuffix(u.Path, "/")
+		cfg.client.baseURL = u
+	})
+}
+
+// WithMaxRetries limite à n les nouvelles tentatives après une réponse 429,
+// au-delà une *RateLimitError est retournée. Par défaut le client attend et
+// réessaie jusqu'au succès
+func WithMaxRetries(n int) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		if n < 0 {
+			cfg.fail(fmt.Errorf("invalid max retries %d", n))
+			return
+		}
+		cfg.client.maxRetries, cfg.client.limitRetries = n, true
+	})
+}
+
+// WithProxy fait passer les requêtes par le proxy proxyURL au lieu du proxy
+// défini par l'environnement (HTTP_PROXY, HTTPS_PROXY, NO_PROXY). Les schémas
+// http, https, socks5 et socks5h (résolution DNS par le proxy) sont acceptés
+func WithProxy(proxyURL *url.URL) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		if proxyURL == nil {
+			cfg.fail(errors.New("missing proxy URL"))
+			return
+		}
+		if !proxySchemes[proxyURL.Scheme] {
+			cfg.fail(fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme))
+			return
+		}
+		cfg.proxy.fn = http.ProxyURL(proxyURL)
+	})
+}
+
+// buildHTTPClient construit le client HTTP à partir des options appliquées. Le
+// transport de base est cloné avant d'être modifié afin de conserver HTTP/2,
+// les réglages de keep-alive et de ne jamais altérer un transport partagé
+func (cfg *clientConfig) buildHTTPClient() (*http.Client, error) {
+	httpClient := &http.Client{Timeout: DefaultTimeout}
+	if cfg.httpClient != nil {
+		clone := *cfg.httpClient
+		httpClient = &clone
+	}
+	if cfg.timeout != nil {
+		httpClient.Timeout = *cfg.timeout
+	}
+	if cfg.transport != nil {
+		httpClient.Transport = cfg.transport
+	}
+
+	if cfg.proxy.enabled() || cfg.tls.enabled() {
+		transport, err := cfg.cloneTransport(httpClient.Transport)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.proxy.enabled() {
+			transport.Proxy = cfg.proxy.proxyFunc()
+		}
+		if cfg.tls.enabled() {
+			transport.TLSClientConfig = cfg.tls.apply(transport.TLSClientConfig)
+		}
+		httpClient.Transport = transport
+	}
+	httpClient.Transport = cfg.chainTransport(httpClient.Transport)
+
+	return httpClient, nil
+}
+
+// cloneTransport retourne une copie modifiable du transport de base
+func (cfg *clientConfig) cloneTransport(base http.RoundTripper) (*http.Transport, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	transport, ok := base.(*http.Transport)
+	if !ok {
+		return nil, errors.New("proxy and TLS options require an *http.Transport")
+	}
+	return transport.Clone(), nil
+}
@@ -0,0 +1,9 @@
+module github.com/peepsii/discord-webhook-go/otel
+
+go 1.22.4
+
+require (
+	github.com/peepsii/discord-webhook-go v0.0.0-20261019082501-97c4cac098d8
+	go.opentelemetry.io/otel v1.31.0
+	go.opentelemetry.io/otel/trace v1.31.0
+)
@@ -0,0 +1,76 @@
+// Package otel adapte un trace.Tracer OpenTelemetry à l'interface
+// discordwebhook.Tracer. Il forme un module distinct afin que le paquet
+// principal reste sans dépendance
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	discordwebhook "github.com/peepsii/discord-webhook-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer implémente discordwebhook.Tracer avec OpenTelemetry
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer adapte tracer, ex:
+//
+//	otel.NewTracer(otelapi.Tracer("github.com/peepsii/discord-webhook-go"))
+func NewTracer(tracer trace.Tracer) *Tracer {
+	return &Tracer{tracer: tracer}
+}
+
+// Start démarre un span OpenTelemetry de type client
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...discordwebhook.Attribute) (context.Context, discordwebhook.Span) {
+	ctx, span := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(convert(attrs)...),
+	)
+	return ctx, &Span{span: span}
+}
+
+// Span implémente discordwebhook.Span
+type Span struct {
+	span trace.Span
+}
+
+// SetAttributes ajoute des attributs au span
+func (s *Span) SetAttributes(attrs ...discordwebhook.Attribute) {
+	s.span.SetAttributes(convert(attrs)...)
+}
+
+// End termine le span en enregistrant err le cas échéant
+func (s *Span) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+// convert traduit les attributs, les types inconnus étant formatés en texte
+func convert(attrs []discordwebhook.Attribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		switch v := a.Value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(a.Key, v))
+		case int:
+			kvs = append(kvs, attribute.Int(a.Key, v))
+		case int64:
+			kvs = append(kvs, attribute.Int64(a.Key, v))
+		case float64:
+			kvs = append(kvs, attribute.Float64(a.Key, v))
+		case bool:
+			kvs = append(kvs, attribute.Bool(a.Key, v))
+		default:
+			kvs = append(kvs, attribute.String(a.Key, fmt.Sprint(v)))
+		}
+	}
+	return kvs
+}
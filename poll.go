@@ -0,0 +1,162 @@
+package discordwebhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Limites imposées par Discord sur les sondages
+const (
+	MaxPollQuestionLength = 300
+	MaxPollAnswers        = 10
+	MaxPollAnswerLength   = 55
+	MaxPollDuration       = 32 * 24 * time.Hour
+)
+
+// PollLayoutType représente la disposition d'un sondage
+type PollLayoutType int
+
+const PollLayoutDefault PollLayoutType = 1
+
+// Poll représente un sondage joint à un message. Expiry et Results ne sont
+// renseignés que sur les messages renvoyés par Discord
+type Poll struct {
+	Question PollMedia    `json:"question"`
+	Answers  []PollAnswer `json:"answers"`
+	// Duration est arrondie à l'heure supérieure, 24 heures par défaut
+	Duration         time.Duration  `json:"-"`
+	AllowMultiselect bool           `json:"allow_multiselect"`
+	LayoutType       PollLayoutType `json:"layout_type,omitempty"`
+	Expiry           *time.Time     `json:"expiry,omitempty"`
+	Results          *PollResults   `json:"results,omitempty"`
+}
+
+// PollMedia représente le texte et l'emoji d'une question ou d'une réponse
+type PollMedia struct {
+	Text  string `json:"text,omitempty"`
+	Emoji *Emoji `json:"emoji,omitempty"`
+}
+
+// PollAnswer représente une réponse proposée. AnswerID est attribué par Discord
+type PollAnswer struct {
+	AnswerID  int       `json:"answer_id,omitempty"`
+	PollMedia PollMedia `json:"poll_media"`
+}
+
+// PollResults représente le décompte des votes d'un sondage
+type PollResults struct {
+	IsFinalized  bool              `json:"is_finalized"`
+	AnswerCounts []PollAnswerCount `json:"answer_counts"`
+}
+
+// PollAnswerCount représente le nombre de votes pour une réponse
+type PollAnswerCount struct {
+	ID      int  `json:"id"`
+	Count   int  `json:"count"`
+	MeVoted bool `json:"me_voted"`
+}
+
+// NewPoll crée un sondage à partir d'une question et de réponses textuelles
+func NewPoll(question string, answers ...string) Poll {
+	poll := Poll{Question: PollMedia{Text: question}, LayoutType: PollLayoutDefault}
+	for _, answer := range answers {
+		poll.Answers = append(poll.Answers, PollAnswer{PollMedia: PollMedia{Text: answer}})
+	}
+	return poll
+}
+
+// AddAnswer ajoute une réponse accompagnée d'un emoji, emoji peut être nil
+func (p *Poll) AddAnswer(text string, emoji *Emoji) *Poll {
+	p.Answers = append(p.Answers, PollAnswer{PollMedia: PollMedia{Text: text, Emoji: emoji}})
+	return p
+}
+
+// MarshalJSON sérialise la durée du sondage en heures comme l'attend Discord
+func (p Poll) MarshalJSON() ([]byte, error) {
+	type poll Poll
+	return json.Marshal(struct {
+		poll
+		Duration int `json:"duration,omitempty"`
+	}{poll(p), durationHours(p.Duration)})
+}
+
+// UnmarshalJSON désérialise le sondage, y compris sa durée en heures
+func (p *Poll) UnmarshalJSON(data []byte) error {
+	type poll Poll
+	aux := struct {
+		*poll
+		Duration int `json:"duration"`
+	}{poll: (*poll)(p)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	p.Duration = time.Duration(aux.Duration) * time.Hour
+	return nil
+}
+
+// durationHours convertit d en heures entières arrondies au supérieur
+func durationHours(d time.Duration) int {
+	return int((d + time.Hour - 1) / time.Hour)
+}
+
+// Finalized indique si le décompte des votes est définitif
+func (p Poll) Finalized() bool {
+	return p.Results != nil && p.Results.IsFinalized
+}
+
+// Votes retourne le nombre de votes pour la réponse answerID
+func (p Poll) Votes(answerID int) int {
+	if p.Results == nil {
+		return 0
+	}
+	for _, count := range p.Results.AnswerCounts {
+		if count.ID == answerID {
+			return count.Count
+		}
+	}
+	return 0
+}
+
+// Winners retourne les réponses ayant reçu le plus de votes, aucune si
+// personne n'a voté
+func (p Poll) Winners() []PollAnswer {
+	var winners []PollAnswer
+	best := 0
+	for _, answer := range p.Answers {
+		votes := p.Votes(answer.AnswerID)
+		switch {
+		case votes == 0:
+		case votes > best:
+			best = votes
+			winners = []PollAnswer{answer}
+		case votes == best:
+			winners = append(winners, answer)
+		}
+	}
+	return winners
+}
+
+func (p Poll) validate(v *validator, path string) {
+	v.required(joinPath(path, "question.text"), p.Question.Text)
+	v.maxLength(joinPath(path, "question.text"), p.Question.Text, MaxPollQuestionLength)
+
+	if len(p.Answers) == 0 || len(p.Answers) > MaxPollAnswers {
+		v.addf(joinPath(path, "answers"), "polls must have 1 to %d answers", MaxPollAnswers)
+	}
+	for i, answer := range p.Answers {
+		answerPath := joinPath(path, fmt.Sprintf("answers[%d].poll_media", i))
+		if answer.PollMedia.Text == "" && answer.PollMedia.Emoji == nil {
+			v.addf(answerPath, "answers must have a text or an emoji")
+		}
+		v.maxLength(joinPath(answerPath, "text"), answer.PollMedia.Text, MaxPollAnswerLength)
+	}
+
+	if p.Duration < 0 || p.Duration > MaxPollDuration {
+		v.addf(joinPath(path, "duration"), "duration %s is not between 1h and %s", p.Duration, MaxPollDuration)
+	}
+	if p.LayoutType != 0 && p.LayoutType != PollLayoutDefault {
+		v.addf(joinPath(path, "layout_type"), "unknown layout type %d", p.LayoutType)
+	}
+}
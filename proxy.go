@@ -0,0 +1,205 @@
+package discordwebhook
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// proxySchemes liste les schémas de proxy pris en charge par le transport
+var proxySchemes = map[string]bool{
+	"http":    true,
+	"https":   true,
+	"socks5":  true,
+	"socks5h": true,
+}
+
+// ProxyFunc choisit le proxy à utiliser pour une requête, nil pour une
+// connexion directe
+type ProxyFunc func(req *http.Request) (*url.URL, error)
+
+// proxyCredentials contient les identifiants du proxy. String ne révèle jamais
+// le mot de passe afin qu'ils ne puissent pas apparaître dans les logs
+type proxyCredentials struct {
+	username string
+	password string
+}
+
+func (p proxyCredentials) String() string {
+	return p.username + ":" + redacted
+}
+
+// GoString masque également le mot de passe pour %#v
+func (p proxyCredentials) GoString() string {
+	return p.String()
+}
+
+// proxyConfig rassemble les options de proxy du client
+type proxyConfig struct {
+	fn          ProxyFunc
+	credentials *proxyCredentials
+	noProxy     []noProxyRule
+}
+
+func (p *proxyConfig) enabled() bool {
+	return p.fn != nil || p.credentials != nil || len(p.noProxy) > 0
+}
+
+// proxyFunc compose le choix du proxy, les exclusions et les identifiants.
+// Sans proxy explicite, celui de l'environnement (HTTP_PROXY...) est utilisé
+func (p *proxyConfig) proxyFunc() ProxyFunc {
+	fn := p.fn
+	if fn == nil {
+		fn = http.ProxyFromEnvironment
+	}
+	credentials, noProxy := p.credentials, p.noProxy
+
+	return func(req *http.Request) (*url.URL, error) {
+		if matchNoProxy(noProxy, req.URL) {
+			return nil, nil
+		}
+
+		proxyURL, err := fn(req)
+		if err != nil || proxyURL == nil {
+			return proxyURL, err
+		}
+		if !proxySchemes[proxyURL.Scheme] {
+			return nil, fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+		}
+
+		if credentials != nil {
+			clone := *proxyURL
+			clone.User = url.UserPassword(credentials.username, credentials.password)
+			proxyURL = &clone
+		}
+		return proxyURL, nil
+	}
+}
+
+// WithProxyFunc choisit le proxy requête par requête, par exemple selon
+// l'hôte de destination. Retourner nil établit une connexion directe
+func WithProxyFunc(fn ProxyFunc) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.proxy.fn = fn
+	})
+}
+
+// WithProxyCredentials authentifie les connexions au proxy. Les identifiants
+// remplacent ceux éventuellement présents dans l'URL du proxy et ne sont
+// jamais inclus dans les messages d'erreur
+func WithProxyCredentials(username, password string) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.proxy.credentials = &proxyCredentials{username: username, password: password}
+	})
+}
+
+// WithProxyCredentialsFile lit les identifiants du proxy depuis un fichier
+// secret contenant "utilisateur:mot de passe"
+func WithProxyCredentialsFile(path string) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			cfg.fail(fmt.Errorf("failed to read proxy credentials: %w", err))
+			return
+		}
+
+		username, password, ok := strings.Cut(strings.TrimSpace(string(data)), ":")
+		if !ok || username == "" {
+			cfg.fail(fmt.Errorf("proxy credentials file %s must contain username:password", path))
+			return
+		}
+		cfg.proxy.credentials = &proxyCredentials{username: username, password: password}
+	})
+}
+
+// WithNoProxy exclut des hôtes du proxy, avec la syntaxe de NO_PROXY : "*",
+// un domaine ("example.com" inclut ses sous-domaines, ".example.com" seulement
+// ses sous-domaines), une IP ou un bloc CIDR, avec un port facultatif. Chaque
+// argument peut contenir une liste séparée par des virgules
+func WithNoProxy(patterns ...string) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		for _, pattern := range patterns {
+			for _, entry := range strings.Split(pattern, ",") {
+				if rule, ok := parseNoProxyRule(entry); ok {
+					cfg.proxy.noProxy = append(cfg.proxy.noProxy, rule)
+				}
+			}
+		}
+	})
+}
+
+// noProxyRule représente une entrée de NO_PROXY
+type noProxyRule struct {
+	all       bool
+	network   *net.IPNet
+	ip        net.IP
+	domain    string
+	matchHost bool
+	port      string
+}
+
+func parseNoProxyRule(entry string) (noProxyRule, bool) {
+	entry = strings.ToLower(strings.TrimSpace(entry))
+	if entry == "" {
+		return noProxyRule{}, false
+	}
+	if entry == "*" {
+		return noProxyRule{all: true}, true
+	}
+	if _, network, err := net.ParseCIDR(entry); err == nil {
+		return noProxyRule{network: network}, true
+	}
+
+	host, port, err := net.SplitHostPort(entry)
+	if err != nil {
+		host, port = entry, ""
+	}
+	host = strings.Trim(host, "[]")
+	if ip := net.ParseIP(host); ip != nil {
+		return noProxyRule{ip: ip, port: port}, true
+	}
+
+	host = strings.TrimPrefix(host, "*")
+	rule := noProxyRule{domain: host, port: port}
+	if !strings.HasPrefix(host, ".") {
+		rule.domain, rule.matchHost = "."+host, true
+	}
+	return rule, true
+}
+
+// matchNoProxy indique si la destination u est exclue du proxy
+func matchNoProxy(rules []noProxyRule, u *url.URL) bool {
+	if len(rules) == 0 || u == nil {
+		return false
+	}
+
+	host, port := strings.ToLower(u.Hostname()), u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+	ip := net.ParseIP(host)
+
+	for _, rule := range rules {
+		switch {
+		case rule.all:
+			return true
+		case rule.network != nil:
+			if ip != nil && rule.network.Contains(ip) {
+				return true
+			}
+		case rule.ip != nil:
+			if ip != nil && rule.ip.Equal(ip) && (rule.port == "" || rule.port == port) {
+				return true
+			}
+		default:
+			matched := strings.HasSuffix(host, rule.domain) || (rule.matchHost && host == rule.domain[1:])
+			if matched && (rule.port == "" || rule.port == port) {
+				return true
+			}
+		}
+	}
+	return false
+}
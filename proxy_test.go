@@ -0,0 +1,86 @@
+package discordwebhook
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestNoProxy(t *testing.T) {
+	tests := []struct {
+		noProxy string
+		target  string
+		direct  bool
+	}{
+		{"*", "https://discord.com/api", true},
+		{"discord.com", "https://discord.com/api", true},
+		{"discord.com", "https://canary.discord.com/api", true},
+		{"discord.com", "https://notdiscord.com/api", false},
+		{".discord.com", "https://discord.com/api", false},
+		{".discord.com", "https://ptb.discord.com/api", true},
+		{"*.discord.com", "https://ptb.discord.com/api", true},
+		{"DISCORD.com", "https://Discord.COM/api", true},
+		{"discord.com:443", "https://discord.com/api", true},
+		{"discord.com:8443", "https://discord.com/api", false},
+		{"discord.com:80", "http://discord.com/api", true},
+		{"example.com, discord.com", "https://discord.com/api", true},
+		{"10.0.0.0/8", "http://10.1.2.3:8080/api", true},
+		{"10.0.0.0/8", "http://192.168.1.2/api", false},
+		{"10.0.0.0/8", "https://discord.com/api", false},
+		{"127.0.0.1", "http://127.0.0.1:8080/api", true},
+		{"127.0.0.1:9090", "http://127.0.0.1:8080/api", false},
+		{"[::1]:8080", "http://[::1]:8080/api", true},
+		{"::1", "http://[::1]:8080/api", true},
+		{"", "https://discord.com/api", false},
+		{" , ", "https://discord.com/api", false},
+	}
+
+	proxy, _ := url.Parse("http://proxy.internal:3128")
+	for _, tt := range tests {
+		t.Run(tt.noProxy+" "+tt.target, func(t *testing.T) {
+			var cfg clientConfig
+			WithProxyFunc(http.ProxyURL(proxy)).apply(&cfg)
+			WithNoProxy(tt.noProxy).apply(&cfg)
+
+			req, err := http.NewRequest(http.MethodPost, tt.target, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := cfg.proxy.proxyFunc()(req)
+			if err != nil {
+				t.Fatalf("proxy error = %v", err)
+			}
+			if direct := got == nil; direct != tt.direct {
+				t.Errorf("proxy = %v, want direct = %v", got, tt.direct)
+			}
+		})
+	}
+}
+
+func TestProxyCredentials(t *testing.T) {
+	var cfg clientConfig
+	WithProxyFunc(http.ProxyURL(&url.URL{Scheme: "http", User: url.UserPassword("old", "old"), Host: "proxy.internal:3128"})).apply(&cfg)
+	WithProxyCredentials("user", "s3cret").apply(&cfg)
+
+	req, _ := http.NewRequest(http.MethodPost, "https://discord.com/api", nil)
+	got, err := cfg.proxy.proxyFunc()(req)
+	if err != nil {
+		t.Fatalf("proxy error = %v", err)
+	}
+	if password, _ := got.User.Password(); got.User.Username() != "user" || password != "s3cret" {
+		t.Errorf("proxy user = %v, want the configured credentials", got.User)
+	}
+	if s := cfg.proxy.credentials.String(); s != "user:"+redacted {
+		t.Errorf("credentials String() = %q, leaks the password", s)
+	}
+}
+
+func TestProxyRejectsUnknownScheme(t *testing.T) {
+	var cfg clientConfig
+	WithProxyFunc(http.ProxyURL(&url.URL{Scheme: "ftp", Host: "proxy.internal"})).apply(&cfg)
+
+	req, _ := http.NewRequest(http.MethodPost, "https://discord.com/api", nil)
+	if _, err := cfg.proxy.proxyFunc()(req); err == nil {
+		t.Error("proxy error = nil, want an unsupported scheme error")
+	}
+}
@@ -0,0 +1,33 @@
+package discordwebhook
+
+import "context"
+
+// Sender regroupe les méthodes d'envoi, de lecture, de modification et de
+// suppression de messages de Client, avec leurs variantes prenant un contexte.
+// Dépendre de Sender plutôt que de *Client permet de substituer en test le faux
+// du paquet discordwebhooktest
+type Sender interface {
+	SendMessage(content string) error
+	SendEmbed(embed DiscordEmbed) error
+	SendEmbedWithFile(embed DiscordEmbed, filename string) error
+	SendFile(filename string) error
+	SendCustomPayload(payload DiscordPayload) error
+	SendCustomPayloadWithFile(payload DiscordPayload, filename string) error
+	SendAndWait(payload DiscordPayload) (*Message, error)
+	GetMessage(messageID string) (*Message, error)
+	EditMessage(messageID string, payload DiscordPayload) (*Message, error)
+	DeleteMessage(messageID string) error
+
+	SendMessageContext(ctx context.Context, content string) error
+	SendEmbedContext(ctx context.Context, embed DiscordEmbed) error
+	SendEmbedWithFileContext(ctx context.Context, embed DiscordEmbed, filename string) error
+	SendFileContext(ctx context.Context, filename string) error
+	SendCustomPayloadContext(ctx context.Context, payload DiscordPayload) error
+	SendCustomPayloadWithFileContext(ctx context.Context, payload DiscordPayload, filename string) error
+	SendAndWaitContext(ctx context.Context, payload DiscordPayload) (*Message, error)
+	GetMessageContext(ctx context.Context, messageID string) (*Message, error)
+	EditMessageContext(ctx context.Context, messageID string, payload DiscordPayload) (*Message, error)
+	DeleteMessageContext(ctx context.Context, messageID string) error
+}
+
+var _ Sender = (*Client)(nil)
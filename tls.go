@@ -0,0 +1,165 @@
+package discordwebhook
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrPinMismatch est retournée lorsqu'aucun certificat présenté par le serveur
+// ne correspond aux clés publiques épinglées
+var ErrPinMismatch = errors.New("no certificate matches the pinned public keys")
+
+// tlsOptions rassemble les options TLS du client
+type tlsOptions struct {
+	rootCAs      *x509.CertPool
+	certificates []tls.Certificate
+	minVersion   uint16
+	pins         map[string]bool
+}
+
+func (t *tlsOptions) enabled() bool {
+	return t.rootCAs != nil || len(t.certificates) > 0 || t.minVersion != 0 || len(t.pins) > 0
+}
+
+// apply complète la configuration TLS du transport, base peut être nil
+func (t *tlsOptions) apply(base *tls.Config) *tls.Config {
+	config := &tls.Config{}
+	if base != nil {
+		config = base.Clone()
+	}
+
+	if t.rootCAs != nil {
+		config.RootCAs = t.rootCAs
+	}
+	config.Certificates = append(config.Certificates, t.certificates...)
+	if t.minVersion != 0 {
+		config.MinVersion = t.minVersion
+	}
+
+	if len(t.pins) > 0 {
+		pins, verify := t.pins, config.VerifyConnection
+		config.VerifyConnection = func(cs tls.ConnectionState) error {
+			if verify != nil {
+				if err := verify(cs); err != nil {
+					return err
+				}
+			}
+			return verifyPins(cs, pins)
+		}
+	}
+
+	return config
+}
+
+// verifyPins vérifie qu'un certificat des chaînes validées correspond à une
+// clé épinglée
+func verifyPins(cs tls.ConnectionState, pins map[string]bool) error {
+	chains := cs.VerifiedChains
+	if len(chains) == 0 {
+		chains = [][]*x509.Certificate{cs.PeerCertificates}
+	}
+	for _, chain := range chains {
+		for _, cert := range chain {
+			if pins[PublicKeyPin(cert)] {
+				return nil
+			}
+		}
+	}
+	return ErrPinMismatch
+}
+
+// PublicKeyPin retourne l'empreinte SHA-256 encodée en base64 de la clé
+// publique (SPKI) de cert, au format attendu par WithPinnedPublicKeys
+func PublicKeyPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// WithRootCAs remplace les autorités de certification de confiance, par
+// exemple par la seule autorité d'une passerelle TLS d'entreprise
+func WithRootCAs(pool *x509.CertPool) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.tls.rootCAs = pool
+	})
+}
+
+// WithRootCAFile ajoute les certificats PEM des fichiers paths aux autorités
+// de confiance du système
+func WithRootCAFile(paths ...string) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		pool := cfg.tls.rootCAs
+		if pool == nil {
+			systemPool, err := x509.SystemCertPool()
+			if err != nil {
+				systemPool = x509.NewCertPool()
+			}
+			pool = systemPool
+		}
+
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				cfg.fail(fmt.Errorf("failed to read root CA: %w", err))
+				return
+			}
+			if !pool.AppendCertsFromPEM(data) {
+				cfg.fail(fmt.Errorf("no PEM certificate found in %s", path))
+				return
+			}
+		}
+		cfg.tls.rootCAs = pool
+	})
+}
+
+// WithClientCertificate présente cert lors des connexions TLS (mTLS)
+func WithClientCertificate(cert tls.Certificate) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.tls.certificates = append(cfg.tls.certificates, cert)
+	})
+}
+
+// WithClientCertificateFiles charge le certificat client et sa clé privée
+// depuis des fichiers PEM
+func WithClientCertificateFiles(certFile, keyFile string) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			cfg.fail(fmt.Errorf("failed to load client certificate: %w", err))
+			return
+		}
+		cfg.tls.certificates = append(cfg.tls.certificates, cert)
+	})
+}
+
+// WithMinTLSVersion définit la version minimale de TLS, ex: tls.VersionTLS13
+func WithMinTLSVersion(version uint16) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.tls.minVersion = version
+	})
+}
+
+// WithPinnedPublicKeys n'accepte que les connexions dont la chaîne de
+// certificats contient l'une des clés publiques pins, exprimées en SHA-256
+// base64 de la SPKI (voir PublicKeyPin), avec ou sans préfixe "sha256/". La
+// vérification habituelle des certificats reste effectuée
+func WithPinnedPublicKeys(pins ...string) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		if cfg.tls.pins == nil {
+			cfg.tls.pins = make(map[string]bool)
+		}
+		for _, pin := range pins {
+			pin = strings.TrimPrefix(strings.TrimSpace(pin), "sha256/")
+			if decoded, err := base64.StdEncoding.DecodeString(pin); err != nil || len(decoded) != sha256.Size {
+				cfg.fail(fmt.Errorf("invalid public key pin %q", pin))
+				return
+			}
+			cfg.tls.pins[pin] = true
+		}
+	})
+}
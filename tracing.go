@@ -0,0 +1,73 @@
+package discordwebhook
+
+import "context"
+
+// Noms des spans créés par le client
+const (
+	// SpanSend couvre une requête logique, middlewares compris
+	SpanSend = "discordwebhook.send"
+	// SpanAttempt couvre une tentative HTTP
+	SpanAttempt = "discordwebhook.attempt"
+	// SpanRateLimitWait couvre l'attente après une réponse 429
+	SpanRateLimitWait = "discordwebhook.rate_limit_wait"
+)
+
+// Attribute est une paire clé/valeur attachée à un span. Value est de type
+// string, int, int64, float64 ou bool
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Attr construit un Attribute
+func Attr(key string, value any) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Tracer crée les spans du client, voir le sous-paquet otel pour
+// OpenTelemetry. Start retourne un contexte portant le span, parent des spans
+// créés ensuite avec ce contexte
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span représente une opération en cours
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	// End termine le span, err est nil en cas de succès
+	End(err error)
+}
+
+// WithTracer trace les requêtes du client avec tracer. Les spans portent l'ID
+// du webhook mais jamais son jeton
+func WithTracer(tracer Tracer) Option {
+	return optionFunc(func(cfg *clientConfig) {
+		cfg.client.tracer = tracer
+	})
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attrs ...Attribute) {}
+
+func (noopSpan) End(err error) {}
+
+type spanKey struct{}
+
+// startSpan démarre un span et le conserve dans le contexte retourné afin que
+// spanFromContext le retrouve
+func (c *Client) startSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	if c.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	ctx, span := c.tracer.Start(ctx, name, attrs...)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// spanFromContext retourne le span courant du client, un span inactif sinon
+func spanFromContext(ctx context.Context) Span {
+	if span, ok := ctx.Value(spanKey{}).(Span); ok {
+		return span
+	}
+	return noopSpan{}
+}
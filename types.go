@@ -1,21 +1,88 @@
 package discordwebhook
 
-import "net/url"
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"time"
+)
 
 // DiscordEmbed représente un embed Discord
 type DiscordEmbed struct {
-	Title       string            `json:"title,omitempty"`
-	Url         string            `json:"url,omitempty"`
-	Description string            `json:"description,omitempty"`
-	Color       int               `json:"color,omitempty"`
-	Image       map[string]string `json:"image,omitempty"`
-	Fields      []EmbedField      `json:"fields,omitempty"`
-	Footer      *EmbedFooter      `json:"footer,omitempty"`
-	Timestamp   string            `json:"timestamp,omitempty"`
-	Thumbnail   map[string]string `json:"thumbnail,omitempty"`
-	Author      EmbedAuthor       `json:"author,omitempty"`
+	Title       string         `json:"title,omitempty"`
+	Type        EmbedType      `json:"type,omitempty"`
+	Url         string         `json:"url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color,omitempty"`
+	Image       *EmbedMedia    `json:"image,omitempty"`
+	Video       *EmbedMedia    `json:"video,omitempty"`
+	Fields      []EmbedField   `json:"fields,omitempty"`
+	Footer      *EmbedFooter   `json:"footer,omitempty"`
+	Timestamp   time.Time      `json:"-"`
+	Thumbnail   *EmbedMedia    `json:"thumbnail,omitempty"`
+	Provider    *EmbedProvider `json:"provider,omitempty"`
+	Author      *EmbedAuthor   `json:"author,omitempty"`
 }
 
+// MarshalJSON sérialise l'embed en omettant l'horodatage lorsqu'il est nul
+func (e DiscordEmbed) MarshalJSON() ([]byte, error) {
+	type embed DiscordEmbed
+	var timestamp string
+	if !e.Timestamp.IsZero() {
+		timestamp = e.Timestamp.Format(time.RFC3339)
+	}
+
+	return json.Marshal(struct {
+		embed
+		Timestamp string `json:"timestamp,omitempty"`
+	}{embed(e), timestamp})
+}
+
+// UnmarshalJSON désérialise l'embed, y compris l'horodatage ISO 8601 renvoyé
+// par Discord
+func (e *DiscordEmbed) UnmarshalJSON(data []byte) error {
+	type embed DiscordEmbed
+	aux := struct {
+		*embed
+		Timestamp string `json:"timestamp"`
+	}{embed: (*embed)(e)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	e.Timestamp = time.Time{}
+	if aux.Timestamp != "" {
+		timestamp, err := time.Parse(time.RFC3339Nano, aux.Timestamp)
+		if err != nil {
+			return fmt.Errorf("invalid embed timestamp: %w", err)
+		}
+		e.Timestamp = timestamp
+	}
+
+	return nil
+}
+
+// EmbedType représente le type d'un embed, les webhooks n'envoient que des
+// embeds EmbedTypeRich
+type EmbedType string
+
+const (
+	EmbedTypeRich    EmbedType = "rich"
+	EmbedTypeImage   EmbedType = "image"
+	EmbedTypeVideo   EmbedType = "video"
+	EmbedTypeGIFV    EmbedType = "gifv"
+	EmbedTypeArticle EmbedType = "article"
+	EmbedTypeLink    EmbedType = "link"
+)
+
+// Couleurs d'embed signalant un succès ou un échec
+const (
+	ColorSuccess = 0x2ecc71
+	ColorFailure = 0xe74c3c
+)
+
 // EmbedField représente un champ d'embed
 type EmbedField struct {
 	Name   string `json:"name"`
@@ -23,16 +90,57 @@ type EmbedField struct {
 	Inline bool   `json:"inline,omitempty"`
 }
 
+// EmbedAuthor représente l'auteur d'un embed
 type EmbedAuthor struct {
-	Name    string `json:"name"`
-	URL     string `json:"url"`
-	IconURL string `json:"icon_url,omitempty"`
+	Name         string `json:"name"`
+	URL          string `json:"url,omitempty"`
+	IconURL      string `json:"icon_url,omitempty"`
+	ProxyIconURL string `json:"proxy_icon_url,omitempty"`
 }
 
 // EmbedFooter représente le footer d'un embed
 type EmbedFooter struct {
-	Text    string `json:"text"`
-	IconURL string `json:"icon_url,omitempty"`
+	Text         string `json:"text"`
+	IconURL      string `json:"icon_url,omitempty"`
+	ProxyIconURL string `json:"proxy_icon_url,omitempty"`
+}
+
+// EmbedMedia représente une image, une miniature ou une vidéo d'embed. Seul
+// URL est envoyé, les autres champs sont renseignés par Discord
+type EmbedMedia struct {
+	URL      string `json:"url"`
+	ProxyURL string `json:"proxy_url,omitempty"`
+	Height   int    `json:"height,omitempty"`
+	Width    int    `json:"width,omitempty"`
+}
+
+// EmbedImage reprend le nom de la structure de la documentation Discord
+//
+// Deprecated: utiliser EmbedMedia, cet alias sera retiré dans la prochaine
+// version mineure
+type EmbedImage = EmbedMedia
+
+// EmbedThumbnail reprend le nom de la structure de la documentation Discord
+//
+// Deprecated: utiliser EmbedMedia, cet alias sera retiré dans la prochaine
+// version mineure
+type EmbedThumbnail = EmbedMedia
+
+// EmbedVideo reprend le nom de la structure de la documentation Discord
+//
+// Deprecated: utiliser EmbedMedia, cet alias sera retiré dans la prochaine
+// version mineure
+type EmbedVideo = EmbedMedia
+
+// Media retourne un EmbedMedia pointant vers url
+func Media(url string) *EmbedMedia {
+	return &EmbedMedia{URL: url}
+}
+
+// EmbedProvider représente le fournisseur d'un embed
+type EmbedProvider struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
 }
 
 // DiscordPayload représente le payload complet à envoyer
@@ -42,6 +150,33 @@ type DiscordPayload struct {
 	Avatar   string         `json:"avatar_url,omitempty"`
 	Content  string         `json:"content,omitempty"`
 	TTS      bool           `json:"tts,omitempty"`
+	Files    []Attachment   `json:"-"`
+	// AllowedMentions restreint les mentions notifiées, le client applique
+	// WebhookOptions.AllowedMentions lorsqu'il est nil
+	AllowedMentions *AllowedMentions `json:"allowed_mentions,omitempty"`
+	// Flags s'ajoute à WebhookOptions.Flags lors de l'envoi
+	Flags MessageFlags `json:"flags,omitempty"`
+	// Components contient des lignes de boutons, ou des composants de mise en
+	// page lorsque Flags contient FlagIsComponentsV2
+	Components Components `json:"components,omitempty"`
+	Poll       *Poll      `json:"poll,omitempty"`
+}
+
+// Attachment représente un fichier joint au message
+type Attachment struct {
+	// Name est le nom du fichier côté Discord, par défaut le nom de Path
+	Name string
+	// Path est lu au moment de l'envoi lorsque Data est vide
+	Path string
+	Data []byte
+}
+
+// filename retourne le nom du fichier côté Discord
+func (a Attachment) filename() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return filepath.Base(a.Path)
 }
 
 // WebhookOptions configure les options du webhook
@@ -49,4 +184,20 @@ type WebhookOptions struct {
 	Username string
 	Avatar   string
 	Proxy    *url.URL
+	// AutoFit ajuste les payloads aux limites de Discord avant l'envoi (voir
+	// DiscordPayload.Fit) au lieu de laisser Discord les rejeter
+	AutoFit bool
+	// AllowedMentions s'applique aux payloads qui n'en définissent pas, par
+	// défaut DefaultAllowedMentions qui ne notifie ni @everyone, ni @here, ni
+	// les rôles
+	AllowedMentions *AllowedMentions
+	// Flags s'ajoute aux flags de chaque payload, ex: FlagSuppressNotifications
+	// pour un client d'alertes de faible priorité
+	Flags MessageFlags
+	// ThreadID envoie les messages dans ce fil du salon du webhook
+	ThreadID string
+	// TTS active la synthèse vocale pour tous les messages
+	TTS bool
+	// Color s'applique aux embeds qui ne définissent pas de couleur
+	Color int
 }
@@ -0,0 +1,181 @@
+package discordwebhook
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationIssue décrit une violation des règles de Discord
+type ValidationIssue struct {
+	// Path désigne l'élément fautif, ex: "embeds[0].fields[3].value"
+	Path    string
+	Message string
+}
+
+func (i ValidationIssue) String() string {
+	if i.Path == "" {
+		return i.Message
+	}
+	return i.Path + ": " + i.Message
+}
+
+// ValidationError est retournée lorsqu'un payload serait rejeté par Discord
+type ValidationError struct {
+	Issues []ValidationIssue
+}
+
+func (e *ValidationError) Error() string {
+	issues := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		issues[i] = issue.String()
+	}
+	return "invalid payload: " + strings.Join(issues, "; ")
+}
+
+// validator accumule les violations relevées lors d'une validation
+type validator struct {
+	issues []ValidationIssue
+}
+
+func (v *validator) addf(path, format string, args ...any) {
+	v.issues = append(v.issues, ValidationIssue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) maxLength(path, s string, max int) {
+	if n := runeCount(s); n > max {
+		v.addf(path, "length %d exceeds %d characters", n, max)
+	}
+}
+
+func (v *validator) required(path, s string) {
+	if strings.TrimSpace(s) == "" {
+		v.addf(path, "must not be empty")
+	}
+}
+
+// reset oublie les violations relevées pour path et ses sous-éléments
+func (v *validator) reset(path string) {
+	issues := v.issues[:0]
+	for _, issue := range v.issues {
+		if issue.Path != path && !strings.HasPrefix(issue.Path, path+".") && !strings.HasPrefix(issue.Path, path+"[") {
+			issues = append(issues, issue)
+		}
+	}
+	v.issues = issues
+}
+
+func (v *validator) err() error {
+	if len(v.issues) == 0 {
+		return nil
+	}
+	return &ValidationError{Issues: append([]ValidationIssue(nil), v.issues...)}
+}
+
+// Validate vérifie que le payload respecte les limites de Discord et retourne
+// une *ValidationError listant toutes les violations relevées
+func (p DiscordPayload) Validate() error {
+	var v validator
+	p.validate(&v)
+	return v.err()
+}
+
+func (p DiscordPayload) validate(v *validator) {
+	if p.Content == "" && len(p.Embeds) == 0 && len(p.Files) == 0 && len(p.Components) == 0 && p.Poll == nil {
+		v.addf("", "message must have content, embeds, components, a poll or files")
+	}
+	v.maxLength("content", p.Content, MaxContentLength)
+	v.maxLength("username", p.Username, MaxUsernameLength)
+
+	if len(p.Embeds) > MaxEmbedsPerMessage {
+		v.addf("embeds", "%d embeds exceed the limit of %d", len(p.Embeds), MaxEmbedsPerMessage)
+	}
+	for i, embed := range p.Embeds {
+		embed.validate(v, fmt.Sprintf("embeds[%d]", i))
+	}
+	if n := embedsLength(p.Embeds); n > MaxEmbedTotalLength {
+		v.addf("embeds", "total length %d exceeds %d characters", n, MaxEmbedTotalLength)
+	}
+
+	if extra := p.Flags &^ webhookFlags; extra != 0 {
+		v.addf("flags", "flags %s cannot be set by webhooks", extra)
+	}
+	if p.Flags.Has(FlagIsComponentsV2) && (p.Content != "" || len(p.Embeds) > 0 || p.Poll != nil) {
+		v.addf("flags", "%s messages cannot have content, embeds or a poll", FlagIsComponentsV2)
+	}
+
+	validateComponents(v, p.Components, p.Flags)
+	if p.Poll != nil {
+		p.Poll.validate(v, "poll")
+	}
+
+	if p.AllowedMentions != nil {
+		p.AllowedMentions.validate(v, "allowed_mentions")
+	}
+
+	if len(p.Files) > MaxFilesPerMessage {
+		v.addf("files", "%d files exceed the limit of %d", len(p.Files), MaxFilesPerMessage)
+	}
+	for i, file := range p.Files {
+		if file.Path == "" && file.Name == "" {
+			v.addf(fmt.Sprintf("files[%d]", i), "attachment must have a name or a path")
+		}
+	}
+}
+
+// Validate vérifie que l'embed respecte les limites de Discord
+func (e DiscordEmbed) Validate() error {
+	var v validator
+	e.validate(&v, "")
+	return v.err()
+}
+
+func (e DiscordEmbed) validate(v *validator, path string) {
+	v.maxLength(joinPath(path, "title"), e.Title, MaxEmbedTitleLength)
+	v.maxLength(joinPath(path, "description"), e.Description, MaxEmbedDescriptionLength)
+	if e.Color < 0 || e.Color > 0xffffff {
+		v.addf(joinPath(path, "color"), "color %#x is not a 24-bit RGB value", e.Color)
+	}
+	if e.Author != nil {
+		v.required(joinPath(path, "author.name"), e.Author.Name)
+		v.maxLength(joinPath(path, "author.name"), e.Author.Name, MaxEmbedAuthorNameLength)
+	}
+	if e.Image != nil {
+		v.required(joinPath(path, "image.url"), e.Image.URL)
+	}
+	if e.Thumbnail != nil {
+		v.required(joinPath(path, "thumbnail.url"), e.Thumbnail.URL)
+	}
+	if e.Video != nil {
+		v.required(joinPath(path, "video.url"), e.Video.URL)
+	}
+	if e.Footer != nil {
+		v.required(joinPath(path, "footer.text"), e.Footer.Text)
+		v.maxLength(joinPath(path, "footer.text"), e.Footer.Text, MaxEmbedFooterLength)
+	}
+
+	if len(e.Fields) > MaxEmbedFields {
+		v.addf(joinPath(path, "fields"), "%d fields exceed the limit of %d", len(e.Fields), MaxEmbedFields)
+	}
+	for i, field := range e.Fields {
+		field.validate(v, joinPath(path, fmt.Sprintf("fields[%d]", i)))
+	}
+
+	if n := e.Length(); n > MaxEmbedTotalLength {
+		v.addf(path, "total length %d exceeds %d characters", n, MaxEmbedTotalLength)
+	}
+}
+
+func (f EmbedField) validate(v *validator, path string) {
+	v.required(joinPath(path, "name"), f.Name)
+	v.maxLength(joinPath(path, "name"), f.Name, MaxEmbedFieldNameLength)
+	v.required(joinPath(path, "value"), f.Value)
+	v.maxLength(joinPath(path, "value"), f.Value, MaxEmbedFieldValueLength)
+}
+
+// joinPath construit le chemin d'un élément à partir de celui de son parent
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
@@ -2,184 +2,645 @@ package discordwebhook
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
-	"path/filepath"
+	"strings"
 	"time"
 )
 
 // Client représente un client webhook Discord
 type Client struct {
-	WebhookURL string
+	WebhookURL WebhookURL
 	Options    WebhookOptions
 	httpClient *http.Client
+	handler    Handler
+	hooks      hookList
+	tracer     Tracer
+	userAgent  string
+	baseURL    *url.URL
+	// maxRetries limite les nouvelles tentatives après une réponse 429
+	// lorsque limitRetries est vrai
+	maxRetries   int
+	limitRetries bool
+	// dryRun est vrai lorsque WithDryRun remplace l'envoi des requêtes
+	dryRun bool
+	// err conserve une erreur de configuration retournée à chaque envoi
+	err error
 }
 
-// NewClient crée un nouveau client webhook
-func NewClient(webhookURL string, options ...WebhookOptions) *Client {
+// NewClient crée un nouveau client webhook configuré par options, ex:
+//
+//	NewClient(webhookURL, WithTimeout(10*time.Second), WebhookOptions{Username: "Bot"})
+//
+// Une URL ou une option invalide n'empêche pas la création du client mais
+// chaque envoi retourne alors l'erreur correspondante, utilisez
+// ParseWebhookURL pour valider l'URL au préalable
+func NewClient(webhookURL string, options ...Option) *Client {
+	parsed, err := ParseWebhookURL(webhookURL)
 	client := &Client{
-		WebhookURL: webhookURL,
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		WebhookURL: parsed,
+		userAgent:  DefaultUserAgent,
 	}
 
-	if len(options) > 0 {
-		client.Options = options[0]
-		if client.Options.Proxy != nil {
-			client.httpClient.Transport = &http.Transport{
-				Proxy: http.ProxyURL(client.Options.Proxy),
-			}
-		}
+	cfg := &clientConfig{client: client, err: err}
+	for _, option := range options {
+		option.apply(cfg)
 	}
 
+	httpClient, err := cfg.buildHTTPClient()
+	if err != nil {
+		cfg.fail(err)
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	client.httpClient = httpClient
+	var handler Handler = HandlerFunc(client.handle)
+	if cfg.dryRun != nil {
+		handler = client.dryRunHandler(cfg.dryRun)
+		client.dryRun = true
+	}
+	client.handler = cfg.chain(handler)
+	client.err = cfg.err
+
 	return client
 }
 
 // SendMessage envoie un message simple
 func (c *Client) SendMessage(content string) error {
+	return c.SendMessageContext(context.Background(), content)
+}
+
+// SendMessageContext est SendMessage avec un contexte, qui peut annuler
+// l'envoi et porte la trace parente des spans du client
+func (c *Client) SendMessageContext(ctx context.Context, content string) error {
 	payload := DiscordPayload{
 		Content:  content,
 		Username: c.Options.Username,
 		Avatar:   c.Options.Avatar,
 	}
 
-	return c.sendPayload(payload, "")
+	return c.sendPayload(ctx, payload, "")
 }
 
 // SendEmbed envoie un embed
 func (c *Client) SendEmbed(embed DiscordEmbed) error {
+	return c.SendEmbedContext(context.Background(), embed)
+}
+
+// SendEmbedContext est SendEmbed avec un contexte, voir SendMessageContext
+func (c *Client) SendEmbedContext(ctx context.Context, embed DiscordEmbed) error {
 	payload := DiscordPayload{
 		Embeds:   []DiscordEmbed{embed},
 		Username: c.Options.Username,
 		Avatar:   c.Options.Avatar,
 	}
 
-	return c.sendPayload(payload, "")
+	return c.sendPayload(ctx, payload, "")
 }
 
 // SendEmbedWithFile envoie un embed avec un fichier
 func (c *Client) SendEmbedWithFile(embed DiscordEmbed, filename string) error {
+	return c.SendEmbedWithFileContext(context.Background(), embed, filename)
+}
+
+// SendEmbedWithFileContext est SendEmbedWithFile avec un contexte, voir
+// SendMessageContext
+func (c *Client) SendEmbedWithFileContext(ctx context.Context, embed DiscordEmbed, filename string) error {
 	payload := DiscordPayload{
 		Embeds:   []DiscordEmbed{embed},
 		Username: c.Options.Username,
 		Avatar:   c.Options.Avatar,
 	}
 
-	return c.sendPayload(payload, filename)
+	return c.sendPayload(ctx, payload, filename)
 }
 
 // SendEmbedWithFile envoie un embed avec un fichier
 func (c *Client) SendFile(filename string) error {
+	return c.SendFileContext(context.Background(), filename)
+}
+
+// SendFileContext est SendFile avec un contexte, voir SendMessageContext
+func (c *Client) SendFileContext(ctx context.Context, filename string) error {
 	payload := DiscordPayload{
 		Username: c.Options.Username,
 		Avatar:   c.Options.Avatar,
 	}
 
-	return c.sendPayload(payload, filename)
+	return c.sendPayload(ctx, payload, filename)
 }
 
 // SendCustomPayload envoie un payload personnalisé
 func (c *Client) SendCustomPayload(payload DiscordPayload) error {
-	return c.sendPayload(payload, "")
+	return c.sendPayload(context.Background(), payload, "")
+}
+
+// SendCustomPayloadContext est SendCustomPayload avec un contexte, voir
+// SendMessageContext
+func (c *Client) SendCustomPayloadContext(ctx context.Context, payload DiscordPayload) error {
+	return c.sendPayload(ctx, payload, "")
 }
 
 // SendCustomPayloadWithFile envoie un payload personnalisé avec un fichier
 func (c *Client) SendCustomPayloadWithFile(payload DiscordPayload, filename string) error {
-	return c.sendPayload(payload, filename)
+	return c.sendPayload(context.Background(), payload, filename)
+}
+
+// SendCustomPayloadWithFileContext est SendCustomPayloadWithFile avec un
+// contexte, voir SendMessageContext
+func (c *Client) SendCustomPayloadWithFileContext(ctx context.Context, payload DiscordPayload, filename string) error {
+	return c.sendPayload(ctx, payload, filename)
+}
+
+// SendAndWait envoie un payload personnalisé et retourne le message créé par
+// Discord, dont l'ID permet par exemple de relire les résultats d'un sondage
+func (c *Client) SendAndWait(payload DiscordPayload) (*Message, error) {
+	return c.send(context.Background(), payload, "", true)
+}
+
+// SendAndWaitContext est SendAndWait avec un contexte, voir SendMessageContext
+func (c *Client) SendAndWaitContext(ctx context.Context, payload DiscordPayload) (*Message, error) {
+	return c.send(ctx, payload, "", true)
+}
+
+// GetMessage récupère un message envoyé par le webhook
+func (c *Client) GetMessage(messageID string) (*Message, error) {
+	return c.GetMessageContext(context.Background(), messageID)
+}
+
+// GetMessageContext est GetMessage avec un contexte, voir SendMessageContext
+func (c *Client) GetMessageContext(ctx context.Context, messageID string) (*Message, error) {
+	return c.do(ctx, &Request{Method: http.MethodGet, MessageID: messageID})
+}
+
+// EditMessage modifie un message envoyé par le webhook. Seuls le contenu, les
+// embeds, les composants, les mentions autorisées et les fichiers joints
+// peuvent être modifiés, les champs vides du payload étant conservés. Le
+// contenu ne peut donc pas être effacé ni tous les embeds retirés
+func (c *Client) EditMessage(messageID string, payload DiscordPayload) (*Message, error) {
+	return c.EditMessageContext(context.Background(), messageID, payload)
+}
+
+// EditMessageContext est EditMessage avec un contexte, voir SendMessageContext
+func (c *Client) EditMessageContext(ctx context.Context, messageID string, payload DiscordPayload) (*Message, error) {
+	if payload.AllowedMentions == nil {
+		payload.AllowedMentions = c.allowedMentions()
+	}
+	return c.do(ctx, &Request{Method: http.MethodPatch, MessageID: messageID, Payload: payload})
+}
+
+// DeleteMessage supprime un message envoyé par le webhook
+func (c *Client) DeleteMessage(messageID string) error {
+	return c.DeleteMessageContext(context.Background(), messageID)
+}
+
+// DeleteMessageContext est DeleteMessage avec un contexte, voir
+// SendMessageContext
+func (c *Client) DeleteMessageContext(ctx context.Context, messageID string) error {
+	_, err := c.do(ctx, &Request{Method: http.MethodDelete, MessageID: messageID})
+	return err
+}
+
+// GetWebhook retourne les informations du webhook : nom, salon et serveur.
+// Comme GetMessage, elle retourne ErrDryRun en mode dry-run. Les middlewares
+// de Handler ne portant que sur les messages, elle ne les traverse pas, mais
+// les hooks, le traceur et les middlewares de transport s'appliquent
+func (c *Client) GetWebhook() (*Webhook, error) {
+	return c.GetWebhookContext(context.Background())
 }
 
-func (c *Client) sendPayload(payload DiscordPayload, filename string) error {
-	body, contentType, err := c.prepareRequest(payload, filename)
+// GetWebhookContext est GetWebhook avec un contexte, voir SendMessageContext
+func (c *Client) GetWebhookContext(ctx context.Context) (*Webhook, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if c.dryRun {
+		return nil, ErrDryRun
+	}
+	endpoint, err := c.endpoint("", nil)
 	if err != nil {
-		return fmt.Errorf("failed to prepare request: %w", err)
+		return nil, err
 	}
 
-	return c.sendWebhookSafe(body, contentType)
+	ctx, span := c.startSpan(ctx, SpanSend,
+		Attr("discord.webhook.id", c.WebhookURL.ID()),
+		Attr("http.request.method", http.MethodGet),
+	)
+	data, err := c.sendWebhookSafe(ctx, RequestInfo{WebhookID: c.WebhookURL.ID(), Method: http.MethodGet}, endpoint, nil, "")
+	span.End(err)
+	if err != nil {
+		return nil, err
+	}
+
+	var webhook Webhook
+	if err := json.Unmarshal(data, &webhook); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook: %w", err)
+	}
+	return &webhook, nil
 }
 
-func (c *Client) prepareRequest(payload DiscordPayload, filename string) (*bytes.Buffer, string, error) {
-	var requestBody bytes.Buffer
-	writer := multipart.NewWriter(&requestBody)
+func (c *Client) sendPayload(ctx context.Context, payload DiscordPayload, filename string) error {
+	_, err := c.send(ctx, payload, filename, false)
+	return err
+}
 
-	// Ajouter le fichier si spécifié
+// send envoie le payload, découpé en plusieurs messages si AutoFit est actif,
+// et retourne le premier message créé lorsque wait est vrai
+func (c *Client) send(ctx context.Context, payload DiscordPayload, filename string, wait bool) (*Message, error) {
+	c.applyDefaults(&payload)
 	if filename != "" {
-		file, err := os.Open(filename)
-		if err != nil {
-			return nil, "", fmt.Errorf("failed to open file: %w", err)
-		}
-		defer file.Close()
+		payload.Files = append(payload.Files[:len(payload.Files):len(payload.Files)], Attachment{Path: filename})
+	}
 
-		part, err := writer.CreateFormFile("file", filepath.Base(filename))
+	payloads := []DiscordPayload{payload}
+	if c.Options.AutoFit {
+		payloads, _ = payload.Fit()
+	}
+
+	var first *Message
+	for i, p := range payloads {
+		message, err := c.do(ctx, &Request{Method: http.MethodPost, Payload: p, Wait: wait})
 		if err != nil {
-			return nil, "", fmt.Errorf("failed to create form file: %w", err)
+			return first, err
+		}
+		if i == 0 {
+			first = message
 		}
+	}
+
+	return first, nil
+}
+
+// do fait passer la requête par la chaîne de middlewares du client
+func (c *Client) do(ctx context.Context, req *Request) (*Message, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	handler := c.handler
+	if handler == nil {
+		handler = HandlerFunc(c.handle)
+	}
+
+	ctx, span := c.startSpan(ctx, SpanSend,
+		Attr("discord.webhook.id", c.WebhookURL.ID()),
+		Attr("http.request.method", req.Method),
+		Attr("discord.embeds", len(req.Payload.Embeds)),
+		Attr("discord.attachments", len(req.Payload.Files)),
+	)
+	message, err := handler.Handle(ctx, req)
+	if message != nil {
+		span.SetAttributes(Attr("discord.message.id", message.ID))
+	}
+	span.End(err)
+	return message, err
+}
 
-		if _, err := io.Copy(part, file); err != nil {
-			return nil, "", fmt.Errorf("failed to copy file: %w", err)
+// handle est le dernier maillon de la chaîne de middlewares : il valide le
+// payload, l'encode et l'envoie au webhook
+func (c *Client) handle(ctx context.Context, req *Request) (*Message, error) {
+	endpoint, err := c.requestEndpoint(req)
+	if err != nil {
+		return nil, err
+	}
+	info := RequestInfo{WebhookID: c.WebhookURL.ID(), Method: req.Method}
+
+	if req.Method == http.MethodGet || req.Method == http.MethodDelete {
+		data, err := c.sendWebhookSafe(ctx, info, endpoint, nil, "")
+		if err != nil || req.Method == http.MethodDelete {
+			return nil, err
 		}
+		return decodeMessage(data)
 	}
 
-	// Ajouter le payload JSON
-	payloadJSON, err := json.Marshal(payload)
+	if err := req.Payload.Validate(); err != nil {
+		return nil, err
+	}
+
+	body, contentType, err := c.prepareRequest(req.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare request: %w", err)
+	}
+
+	info.Attachments = len(req.Payload.Files)
+	spanFromContext(ctx).SetAttributes(Attr("discord.payload.size", body.Len()))
+	data, err := c.sendWebhookSafe(ctx, info, endpoint, &body.Buffer, contentType)
+	body.release()
+	if err != nil || (req.Method == http.MethodPost && !req.Wait) {
+		return nil, err
+	}
+
+	return decodeMessage(data)
+}
+
+// requestEndpoint construit l'URL ciblée par req
+func (c *Client) requestEndpoint(req *Request) (string, error) {
+	path := ""
+	if req.MessageID != "" {
+		path = "/messages/" + url.PathEscape(req.MessageID)
+	}
+	query := url.Values{}
+	if req.Wait {
+		query.Set("wait", "true")
+	}
+	if c.Options.ThreadID != "" {
+		query.Set("thread_id", c.Options.ThreadID)
+	}
+	return c.endpoint(path, query)
+}
+
+// endpoint construit l'URL d'une ressource du webhook, sans le fil qui ne
+// concerne que les messages
+func (c *Client) endpoint(path string, query url.Values) (string, error) {
+	if c.err != nil {
+		return "", c.err
+	}
+	if c.WebhookURL.IsZero() {
+		return "", fmt.Errorf("%w: missing webhook URL", ErrInvalidWebhookURL)
+	}
+
+	u, err := url.Parse(c.WebhookURL.endpoint())
 	if err != nil {
+		return "", fmt.Errorf("%w: malformed URL", ErrInvalidWebhookURL)
+	}
+	if c.baseURL != nil {
+		u.Scheme, u.Host = c.baseURL.Scheme, c.baseURL.Host
+		u.Path = c.baseURL.Path + u.Path
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	values := u.Query()
+	for key, value := range query {
+		values[key] = value
+	}
+	u.RawQuery = values.Encode()
+
+	return u.String(), nil
+}
+
+func decodeMessage(data []byte) (*Message, error) {
+	var message Message
+	if err := json.Unmarshal(data, &message); err != nil {
+		return nil, fmt.Errorf("failed to decode message: %w", err)
+	}
+	return &message, nil
+}
+
+// applyDefaults complète le payload avec les valeurs par défaut du client
+func (c *Client) applyDefaults(payload *DiscordPayload) {
+	if payload.AllowedMentions == nil {
+		payload.AllowedMentions = c.allowedMentions()
+	}
+	if payload.Username == "" {
+		payload.Username = c.Options.Username
+	}
+	if payload.Avatar == "" {
+		payload.Avatar = c.Options.Avatar
+	}
+	payload.TTS = payload.TTS || c.Options.TTS
+	payload.Flags |= c.Options.Flags
+
+	if c.Options.Color != 0 {
+		embeds := make([]DiscordEmbed, len(payload.Embeds))
+		for i, embed := range payload.Embeds {
+			if embed.Color == 0 {
+				embed.Color = c.Options.Color
+			}
+			embeds[i] = embed
+		}
+		payload.Embeds = embeds
+	}
+}
+
+// allowedMentions retourne les mentions autorisées par défaut du client
+func (c *Client) allowedMentions() *AllowedMentions {
+	if c.Options.AllowedMentions != nil {
+		return c.Options.AllowedMentions
+	}
+	return DefaultAllowedMentions()
+}
+
+// prepareRequest encode le payload en JSON, ou en multipart/form-data avec un
+// champ payload_json lorsque des fichiers sont joints. Le buffer retourné
+// provient du pool et doit être rendu par release après l'envoi
+func (c *Client) prepareRequest(payload DiscordPayload) (*requestBuffer, string, error) {
+	if len(payload.Files) == 0 {
+		body := getBuffer()
+		if err := body.encode(payload); err != nil {
+			body.release()
+			return nil, "", fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		return body, "application/json", nil
+	}
+
+	payloadJSON := getBuffer()
+	defer payloadJSON.release()
+	if err := payloadJSON.encode(payload); err != nil {
 		return nil, "", fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	if err := writer.WriteField("payload_json", string(payloadJSON)); err != nil {
-		return nil, "", fmt.Errorf("failed to write payload field: %w", err)
+	body := getBuffer()
+	contentType, err := writeMultipart(&body.Buffer, payload.Files, payloadJSON.Bytes())
+	if err != nil {
+		body.release()
+		return nil, "", err
+	}
+	return body, contentType, nil
+}
+
+// writeMultipart écrit les fichiers joints et le payload JSON dans w
+func writeMultipart(w io.Writer, files []Attachment, payloadJSON []byte) (string, error) {
+	writer := multipart.NewWriter(w)
+
+	// Ajouter les fichiers joints
+	for i, file := range files {
+		if err := writeAttachment(writer, fmt.Sprintf("files[%d]", i), file); err != nil {
+			return "", err
+		}
+	}
+
+	// Ajouter le payload JSON
+	part, err := writer.CreateFormField("payload_json")
+	if err != nil {
+		return "", fmt.Errorf("failed to write payload field: %w", err)
+	}
+	if _, err := part.Write(payloadJSON); err != nil {
+		return "", fmt.Errorf("failed to write payload field: %w", err)
 	}
 
 	if err := writer.Close(); err != nil {
-		return nil, "", fmt.Errorf("failed to close writer: %w", err)
+		return "", fmt.Errorf("failed to close writer: %w", err)
 	}
 
-	return &requestBody, writer.FormDataContentType(), nil
+	return writer.FormDataContentType(), nil
 }
 
-func (c *Client) sendWebhookSafe(body *bytes.Buffer, contentType string) error {
-	originalBody := bytes.NewReader(body.Bytes())
+// writeAttachment ajoute le contenu d'un fichier joint au formulaire
+func writeAttachment(writer *multipart.Writer, field string, file Attachment) error {
+	part, err := writer.CreateFormFile(field, file.filename())
+	if err != nil {
+		return fmt.Errorf("failed to create form file: %w", err)
+	}
+
+	if file.Data != nil || file.Path == "" {
+		_, err := part.Write(file.Data)
+		return err
+	}
+
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(part, f); err != nil {
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	return nil
+}
 
-	for {
-		// Reset reader for retry
-		originalBody.Seek(0, 0)
+// sendWebhookSafe envoie la requête en respectant les limites de débit et
+// notifie les hooks du client
+func (c *Client) sendWebhookSafe(ctx context.Context, info RequestInfo, endpoint string, body *bytes.Buffer, contentType string) (data []byte, err error) {
+	var bodies *bodyReaders
+	var status int
+	var rateLimit RateLimit
+	start := time.Now()
+	defer func() {
+		if bodies != nil {
+			bodies.close()
+		}
+		c.hooks.result(ResultInfo{
+			RequestInfo: info,
+			StatusCode:  status,
+			RateLimit:   rateLimit,
+			Latency:     time.Since(start),
+			Err:         err,
+		})
+	}()
+
+	if body != nil {
+		info.Size = body.Len()
+		bodies = &bodyReaders{b: body.Bytes()}
+	}
 
-		req, err := http.NewRequest("POST", c.WebhookURL, originalBody)
+	for info.Attempt = 1; ; info.Attempt++ {
+		attemptCtx, span := c.startSpan(ctx, SpanAttempt,
+			Attr("discord.attempt", info.Attempt),
+			Attr("discord.payload.size", info.Size),
+		)
+		req, err := http.NewRequestWithContext(attemptCtx, info.Method, endpoint, nil)
 		if err != nil {
-			return fmt.Errorf("failed to create request: %w", err)
+			err = fmt.Errorf("failed to create request: %w", c.redactError(err))
+			span.End(err)
+			return nil, err
 		}
 
-		req.Header.Set("Content-Type", contentType)
-		req.Header.Set("User-Agent", "DiscordWebhook-Go/1.0")
+		if bodies != nil {
+			// Un nouveau lecteur par tentative, GetBody permettant au
+			// transport de renvoyer la requête sur une autre connexion
+			req.Body, _ = bodies.open()
+			req.GetBody = bodies.open
+			req.ContentLength = int64(body.Len())
+		}
+
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		req.Header.Set("User-Agent", c.userAgent)
 
+		c.hooks.request(info)
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
-			return fmt.Errorf("failed to send request: %w", err)
+			err = fmt.Errorf("failed to send request: %w", c.redactError(err))
+			span.End(err)
+			return nil, err
 		}
+		status, rateLimit = resp.StatusCode, parseRateLimit(resp.Header)
+		span.SetAttributes(Attr("http.response.status_code", resp.StatusCode))
 
 		if resp.StatusCode == 429 {
-			var rateLimit struct {
+			var limited struct {
 				RetryAfter float64 `json:"retry_after"`
+				Global     bool    `json:"global"`
 			}
-			json.NewDecoder(resp.Body).Decode(&rateLimit)
+			json.NewDecoder(resp.Body).Decode(&limited)
 			resp.Body.Close()
+			span.End(nil)
+			rateLimit.Global = rateLimit.Global || limited.Global
 
-			wait := time.Duration(rateLimit.RetryAfter*1000) * time.Millisecond
+			wait := time.Duration(limited.RetryAfter*1000) * time.Millisecond
+			if wait == 0 {
+				wait = parseSeconds(resp.Header.Get("Retry-After"))
+			}
 			if wait == 0 {
 				wait = 1 * time.Second
 			}
-			time.Sleep(wait)
+			c.hooks.rateLimit(RateLimitInfo{RequestInfo: info, RateLimit: rateLimit, Wait: wait})
+			if c.limitRetries && info.Attempt > c.maxRetries {
+				return nil, &RateLimitError{RetryAfter: wait, RateLimit: rateLimit}
+			}
+
+			_, waitSpan := c.startSpan(ctx, SpanRateLimitWait,
+				Attr("discord.rate_limit.wait_ms", wait.Milliseconds()),
+				Attr("discord.rate_limit.bucket", rateLimit.Bucket),
+				Attr("discord.rate_limit.scope", rateLimit.Scope),
+				Attr("discord.rate_limit.global", rateLimit.Global),
+			)
+			err := sleep(ctx, wait)
+			waitSpan.End(err)
+			if err != nil {
+				return nil, err
+			}
+
+			retry := info
+			retry.Attempt++
+			c.hooks.retry(RetryInfo{RequestInfo: retry, Wait: wait, StatusCode: resp.StatusCode})
 			continue
 		}
 
+		data, err := io.ReadAll(resp.Body)
 		resp.Body.Close()
 
 		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			return nil
+			if err != nil {
+				err = fmt.Errorf("failed to read response: %w", err)
+				span.End(err)
+				return nil, err
+			}
+			span.End(nil)
+			return data, nil
 		}
 
-		return fmt.Errorf("webhook failed with status: %s", resp.Status)
+		apiErr := newAPIError(resp.StatusCode, resp.Status, data)
+		span.End(apiErr)
+		return nil, apiErr
+	}
+}
+
+// sleep attend d durant au plus la durée de vie de ctx
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// redactError masque le jeton du webhook dans l'URL des erreurs HTTP
+func (c *Client) redactError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = c.WebhookURL.redact(urlErr.URL)
 	}
+	return err
 }
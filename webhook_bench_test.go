@@ -0,0 +1,75 @@
+package discordwebhook_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	discordwebhook "github.com/peepsii/discord-webhook-go"
+)
+
+// benchmarkEmbed est l'embed envoyé par les benchmarks
+var benchmarkEmbed = discordwebhook.DiscordEmbed{
+	Title:       "Deployment Successful",
+	Description: "Application version v1.2.3",
+	Color:       0x00ff00,
+	Fields: []discordwebhook.EmbedField{
+		{Name: "Version", Value: "v1.2.3", Inline: true},
+		{Name: "Environment", Value: "Production", Inline: true},
+		{Name: "Duration", Value: "2m 34s", Inline: true},
+	},
+	Footer:    &discordwebhook.EmbedFooter{Text: "CI/CD Pipeline"},
+	Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// newBenchmarkClient retourne un client envoyant ses requêtes à un serveur
+// local qui lit le corps et répond 204 No Content
+func newBenchmarkClient(b *testing.B) *discordwebhook.Client {
+	b.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	b.Cleanup(server.Close)
+
+	return discordwebhook.NewClient(
+		"https://discord.com/api/webhooks/123456789012345678/benchmark-token",
+		discordwebhook.WithBaseURL(server.URL),
+	)
+}
+
+func benchmarkSend(b *testing.B, payload discordwebhook.DiscordPayload) {
+	client := newBenchmarkClient(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := client.SendCustomPayload(payload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSendMessage mesure l'envoi d'un simple texte, encodé en
+// application/json
+func BenchmarkSendMessage(b *testing.B) {
+	benchmarkSend(b, discordwebhook.DiscordPayload{Content: "Deployment of v1.2.3 finished"})
+}
+
+// BenchmarkSendEmbed mesure l'envoi d'un embed sans fichier joint, encodé en
+// application/json
+func BenchmarkSendEmbed(b *testing.B) {
+	benchmarkSend(b, discordwebhook.DiscordPayload{
+		Embeds: []discordwebhook.DiscordEmbed{benchmarkEmbed},
+	})
+}
+
+// BenchmarkSendFile mesure le même embed avec un fichier joint, encodé en
+// multipart/form-data comme l'étaient tous les envois auparavant
+func BenchmarkSendFile(b *testing.B) {
+	benchmarkSend(b, discordwebhook.DiscordPayload{
+		Embeds: []discordwebhook.DiscordEmbed{benchmarkEmbed},
+		Files:  []discordwebhook.Attachment{{Name: "status.txt", Data: []byte("Status: OK\n")}},
+	})
+}
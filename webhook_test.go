@@ -0,0 +1,268 @@
+package discordwebhook_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	discordwebhook "github.com/peepsii/discord-webhook-go"
+	"github.com/peepsii/discord-webhook-go/discordwebhooktest"
+)
+
+// newServer démarre un faux serveur Discord fermé à la fin du test
+func newServer(t *testing.T) *discordwebhooktest.Server {
+	t.Helper()
+	server := discordwebhooktest.NewServer()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSendRetriesAfterRateLimit(t *testing.T) {
+	server := newServer(t)
+	server.Enqueue(
+		discordwebhooktest.RateLimited(10*time.Millisecond, false),
+		discordwebhooktest.RateLimited(10*time.Millisecond, true),
+	)
+
+	if err := server.Client().SendMessage("hello"); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	requests := server.Requests()
+	if len(requests) != 3 {
+		t.Fatalf("got %d requests, want 3", len(requests))
+	}
+	for _, req := range requests {
+		if req.Payload.Content != "hello" {
+			t.Errorf("retried content = %q, want %q", req.Payload.Content, "hello")
+		}
+	}
+}
+
+func TestSendReturnsRateLimitErrorAfterMaxRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+		global     bool
+	}{
+		{"no retry", 0, false},
+		{"one retry", 1, false},
+		{"global", 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newServer(t)
+			for i := 0; i <= tt.maxRetries; i++ {
+				server.Enqueue(discordwebhooktest.RateLimited(20*time.Millisecond, tt.global))
+			}
+
+			err := server.Client(discordwebhook.WithMaxRetries(tt.maxRetries)).SendMessage("hello")
+			var rateLimitErr *discordwebhook.RateLimitError
+			if !errors.As(err, &rateLimitErr) {
+				t.Fatalf("SendMessage() error = %v, want *RateLimitError", err)
+			}
+			if rateLimitErr.RetryAfter != 20*time.Millisecond {
+				t.Errorf("RetryAfter = %s, want 20ms", rateLimitErr.RetryAfter)
+			}
+			if rateLimitErr.RateLimit.Global != tt.global {
+				t.Errorf("RateLimit.Global = %v, want %v", rateLimitErr.RateLimit.Global, tt.global)
+			}
+			if n := len(server.Requests()); n != tt.maxRetries+1 {
+				t.Errorf("got %d requests, want %d", n, tt.maxRetries+1)
+			}
+		})
+	}
+}
+
+func TestSendReturnsAPIErrorOnServerError(t *testing.T) {
+	for _, status := range []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			server := newServer(t)
+			server.Enqueue(discordwebhooktest.ServerError(status))
+
+			err := server.Client().SendMessage("hello")
+			var apiErr *discordwebhook.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("SendMessage() error = %v, want *APIError", err)
+			}
+			if apiErr.StatusCode != status {
+				t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, status)
+			}
+			if n := len(server.Requests()); n != 1 {
+				t.Errorf("got %d requests, want 1", n)
+			}
+		})
+	}
+}
+
+func TestSendValidatesBeforeSending(t *testing.T) {
+	server := newServer(t)
+	err := server.Client().SendCustomPayload(discordwebhook.DiscordPayload{})
+	var validationErr *discordwebhook.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("SendCustomPayload() error = %v, want *ValidationError", err)
+	}
+	if n := len(server.Requests()); n != 0 {
+		t.Errorf("got %d requests, want 0", n)
+	}
+}
+
+func TestSendWaitsOnlyWhenAsked(t *testing.T) {
+	server := newServer(t)
+	client := server.Client()
+
+	if err := client.SendMessage("fire and forget"); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	message, err := client.SendAndWait(discordwebhook.DiscordPayload{Content: "waited"})
+	if err != nil {
+		t.Fatalf("SendAndWait() error = %v", err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 2 || requests[0].Wait || !requests[1].Wait {
+		t.Fatalf("wait flags = %+v, want false then true", requests)
+	}
+	if message.ID == "" || message.Content != "waited" || message.ChannelID != discordwebhooktest.ServerChannelID {
+		t.Errorf("SendAndWait() = %+v", message)
+	}
+	if message.WebhookID != discordwebhooktest.ServerWebhookID {
+		t.Errorf("WebhookID = %q, want %q", message.WebhookID, discordwebhooktest.ServerWebhookID)
+	}
+	if server.Message(message.ID) == nil {
+		t.Errorf("message %s not stored by the server", message.ID)
+	}
+}
+
+func TestEditMessage(t *testing.T) {
+	server := newServer(t)
+	client := server.Client()
+	sent, err := client.SendAndWait(discordwebhook.DiscordPayload{
+		Content: "Deploying",
+		Embeds:  []discordwebhook.DiscordEmbed{{Title: "v1.2.3"}},
+		Files:   []discordwebhook.Attachment{{Name: "build.log", Data: []byte("ok")}},
+	})
+	if err != nil {
+		t.Fatalf("SendAndWait() error = %v", err)
+	}
+
+	edited, err := client.EditMessage(sent.ID, discordwebhook.DiscordPayload{
+		Content: "Deployed",
+		Files:   []discordwebhook.Attachment{{Name: "report.txt", Data: []byte("done")}},
+	})
+	if err != nil {
+		t.Fatalf("EditMessage() error = %v", err)
+	}
+	if edited.Content != "Deployed" {
+		t.Errorf("Content = %q, want %q", edited.Content, "Deployed")
+	}
+	if len(edited.Embeds) != 1 || edited.Embeds[0].Title != "v1.2.3" {
+		t.Errorf("Embeds = %+v, want the original embed kept", edited.Embeds)
+	}
+	if edited.EditedTimestamp == nil {
+		t.Error("EditedTimestamp not set")
+	}
+	if len(edited.Attachments) != 2 {
+		t.Fatalf("got %d attachments, want 2", len(edited.Attachments))
+	}
+	if edited.Attachments[0].ID == edited.Attachments[1].ID {
+		t.Errorf("attachments share the ID %s", edited.Attachments[0].ID)
+	}
+
+	requests := server.Requests()
+	if last := requests[len(requests)-1]; last.Method != http.MethodPatch || last.MessageID != sent.ID {
+		t.Errorf("last request = %s %s, want PATCH %s", last.Method, last.MessageID, sent.ID)
+	}
+}
+
+func TestGetAndDeleteMessage(t *testing.T) {
+	server := newServer(t)
+	client := server.Client()
+	sent, err := client.SendAndWait(discordwebhook.DiscordPayload{Content: "hello"})
+	if err != nil {
+		t.Fatalf("SendAndWait() error = %v", err)
+	}
+
+	got, err := client.GetMessage(sent.ID)
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	if got.ID != sent.ID || got.Content != "hello" {
+		t.Errorf("GetMessage() = %+v, want message %s", got, sent.ID)
+	}
+
+	if err := client.DeleteMessage(sent.ID); err != nil {
+		t.Fatalf("DeleteMessage() error = %v", err)
+	}
+	if server.Message(sent.ID) != nil {
+		t.Error("message still stored after DeleteMessage")
+	}
+
+	for name, call := range map[string]func() error{
+		"get": func() error {
+			_, err := client.GetMessage(sent.ID)
+			return err
+		},
+		"edit": func() error {
+			_, err := client.EditMessage(sent.ID, discordwebhook.DiscordPayload{Content: "x"})
+			return err
+		},
+		"delete": func() error {
+			return client.DeleteMessage(sent.ID)
+		},
+	} {
+		var apiErr *discordwebhook.APIError
+		if err := call(); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Code != 10008 {
+			t.Errorf("%s deleted message error = %v, want 404 Unknown Message", name, err)
+		}
+	}
+}
+
+func TestGetWebhook(t *testing.T) {
+	server := newServer(t)
+	webhook, err := server.Client(discordwebhook.WebhookOptions{ThreadID: "42"}).GetWebhook()
+	if err != nil {
+		t.Fatalf("GetWebhook() error = %v", err)
+	}
+	if webhook.ID != discordwebhooktest.ServerWebhookID || webhook.Name != discordwebhooktest.ServerWebhookName {
+		t.Errorf("GetWebhook() = %+v", webhook)
+	}
+	if webhook.ChannelID != discordwebhooktest.ServerChannelID || webhook.GuildID != discordwebhooktest.ServerGuildID {
+		t.Errorf("channel and guild = %s, %s", webhook.ChannelID, webhook.GuildID)
+	}
+	if req := server.Requests()[0]; req.Method != http.MethodGet || req.ThreadID != "" {
+		t.Errorf("request = %s with thread %q, want GET without thread", req.Method, req.ThreadID)
+	}
+}
+
+func TestGetWebhookWithInvalidToken(t *testing.T) {
+	server := newServer(t)
+	client := discordwebhook.NewClient(
+		"https://discord.com/api/webhooks/"+discordwebhooktest.ServerWebhookID+"/wrong-token",
+		discordwebhook.WithBaseURL(server.URL),
+	)
+	var apiErr *discordwebhook.APIError
+	if _, err := client.GetWebhook(); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("GetWebhook() error = %v, want 401", err)
+	}
+}
+
+func TestThread(t *testing.T) {
+	server := newServer(t)
+	client := server.Client(discordwebhook.WebhookOptions{ThreadID: "42"})
+	message, err := client.SendAndWait(discordwebhook.DiscordPayload{Content: "in thread"})
+	if err != nil {
+		t.Fatalf("SendAndWait() error = %v", err)
+	}
+	if message.ChannelID != "42" {
+		t.Errorf("ChannelID = %q, want the thread 42", message.ChannelID)
+	}
+	if _, err := client.EditMessage(message.ID, discordwebhook.DiscordPayload{Content: "edited"}); err != nil {
+		t.Fatalf("EditMessage() error = %v", err)
+	}
+	for _, req := range server.Requests() {
+		if req.ThreadID != "42" {
+			t.Errorf("%s request thread = %q, want 42", req.Method, req.ThreadID)
+		}
+	}
+}
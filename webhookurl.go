@@ -0,0 +1,170 @@
+package discordwebhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidWebhookURL est retournée lorsqu'une URL de webhook ne peut pas être
+// analysée. Le message d'erreur ne reprend jamais l'URL fournie
+var ErrInvalidWebhookURL = errors.New("invalid webhook URL")
+
+// redacted remplace le jeton du webhook dans les URLs affichées
+const redacted = "REDACTED"
+
+// discordHosts liste les hôtes acceptés pour une URL de webhook
+var discordHosts = map[string]bool{
+	"discord.com":           true,
+	"ptb.discord.com":       true,
+	"canary.discord.com":    true,
+	"discordapp.com":        true,
+	"ptb.discordapp.com":    true,
+	"canary.discordapp.com": true,
+}
+
+// WebhookURL représente l'URL d'un webhook Discord, normalisée vers
+// discord.com. Son jeton n'apparaît jamais dans String, MarshalJSON ni dans les
+// erreurs du client
+type WebhookURL struct {
+	id      string
+	token   string
+	version int
+}
+
+// ParseWebhookURL analyse une URL de webhook de la forme
+// https://discord.com/api[/v10]/webhooks/<id>/<token>. Les hôtes
+// discordapp.com, ptb et canary sont acceptés et normalisés vers discord.com
+func ParseWebhookURL(raw string) (WebhookURL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return WebhookURL{}, fmt.Errorf("%w: malformed URL", ErrInvalidWebhookURL)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return WebhookURL{}, fmt.Errorf("%w: unsupported scheme %q", ErrInvalidWebhookURL, u.Scheme)
+	}
+	if host := strings.ToLower(strings.TrimPrefix(u.Hostname(), "www.")); !discordHosts[host] {
+		return WebhookURL{}, fmt.Errorf("%w: unexpected host %q", ErrInvalidWebhookURL, u.Hostname())
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segments) > 0 && segments[0] == "api" {
+		segments = segments[1:]
+	} else {
+		return WebhookURL{}, fmt.Errorf("%w: path must start with /api", ErrInvalidWebhookURL)
+	}
+
+	var webhookURL WebhookURL
+	if len(segments) > 0 && strings.HasPrefix(segments[0], "v") {
+		version, err := strconv.Atoi(segments[0][1:])
+		if err != nil || version <= 0 {
+			return WebhookURL{}, fmt.Errorf("%w: invalid API version", ErrInvalidWebhookURL)
+		}
+		webhookURL.version = version
+		segments = segments[1:]
+	}
+
+	if len(segments) != 3 || segments[0] != "webhooks" {
+		return WebhookURL{}, fmt.Errorf("%w: path must be /api/webhooks/<id>/<token>", ErrInvalidWebhookURL)
+	}
+	webhookURL.id, webhookURL.token = segments[1], segments[2]
+
+	if err := webhookURL.validate(); err != nil {
+		return WebhookURL{}, err
+	}
+	return webhookURL, nil
+}
+
+func (u WebhookURL) validate() error {
+	if u.id == "" {
+		return fmt.Errorf("%w: missing webhook ID", ErrInvalidWebhookURL)
+	}
+	if _, err := strconv.ParseUint(u.id, 10, 64); err != nil {
+		return fmt.Errorf("%w: webhook ID must be numeric", ErrInvalidWebhookURL)
+	}
+	if u.token == "" {
+		return fmt.Errorf("%w: missing webhook token", ErrInvalidWebhookURL)
+	}
+	// Une URL relue après MarshalJSON porte le jeton masqué
+	if u.token == redacted {
+		return fmt.Errorf("%w: webhook token is redacted", ErrInvalidWebhookURL)
+	}
+	for _, r := range u.token {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return fmt.Errorf("%w: webhook token contains invalid characters", ErrInvalidWebhookURL)
+		}
+	}
+	return nil
+}
+
+// ID retourne l'identifiant du webhook
+func (u WebhookURL) ID() string {
+	return u.id
+}
+
+// Version retourne la version de l'API présente dans l'URL, 0 si absente
+func (u WebhookURL) Version() int {
+	return u.version
+}
+
+// IsZero indique si l'URL n'a pas été initialisée
+func (u WebhookURL) IsZero() bool {
+	return u.id == "" && u.token == ""
+}
+
+// String retourne l'URL normalisée avec le jeton masqué
+func (u WebhookURL) String() string {
+	if u.IsZero() {
+		return ""
+	}
+	return u.build(redacted)
+}
+
+// GoString masque également le jeton pour %#v
+func (u WebhookURL) GoString() string {
+	return fmt.Sprintf("discordwebhook.WebhookURL(%q)", u.String())
+}
+
+// MarshalJSON sérialise l'URL avec le jeton masqué
+func (u WebhookURL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.String())
+}
+
+// UnmarshalJSON analyse une URL de webhook complète, par exemple depuis un
+// fichier de configuration. L'URL masquée produite par MarshalJSON est refusée
+func (u *WebhookURL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	parsed, err := ParseWebhookURL(raw)
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
+
+// endpoint retourne l'URL réelle du webhook, jeton compris
+func (u WebhookURL) endpoint() string {
+	return u.build(url.PathEscape(u.token))
+}
+
+func (u WebhookURL) build(token string) string {
+	api := "/api"
+	if u.version > 0 {
+		api += "/v" + strconv.Itoa(u.version)
+	}
+	return "https://discord.com" + api + "/webhooks/" + u.id + "/" + token
+}
+
+// redact masque le jeton du webhook dans s
+func (u WebhookURL) redact(s string) string {
+	if u.token == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, u.token, redacted)
+}
@@ -0,0 +1,94 @@
+package discordwebhook_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	discordwebhook "github.com/peepsii/discord-webhook-go"
+)
+
+func TestParseWebhookURL(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		version int
+	}{
+		{"https://discord.com/api/webhooks/123/token", "https://discord.com/api/webhooks/123/REDACTED", 0},
+		{"https://discordapp.com/api/webhooks/123/token", "https://discord.com/api/webhooks/123/REDACTED", 0},
+		{"https://ptb.discord.com/api/webhooks/123/token", "https://discord.com/api/webhooks/123/REDACTED", 0},
+		{"https://canary.discordapp.com/api/webhooks/123/token", "https://discord.com/api/webhooks/123/REDACTED", 0},
+		{"https://www.discord.com/api/webhooks/123/token", "https://discord.com/api/webhooks/123/REDACTED", 0},
+		{"https://discord.com/api/v10/webhooks/123/token", "https://discord.com/api/v10/webhooks/123/REDACTED", 10},
+		{"  https://discord.com/api/webhooks/123/to-k_e.n/  ", "https://discord.com/api/webhooks/123/REDACTED", 0},
+		{"http://DISCORD.com/api/webhooks/123/token", "https://discord.com/api/webhooks/123/REDACTED", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			u, err := discordwebhook.ParseWebhookURL(tt.raw)
+			if err != nil {
+				t.Fatalf("ParseWebhookURL() error = %v", err)
+			}
+			if got := u.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if u.ID() != "123" || u.Version() != tt.version {
+				t.Errorf("ID(), Version() = %q, %d, want 123, %d", u.ID(), u.Version(), tt.version)
+			}
+		})
+	}
+}
+
+func TestParseWebhookURLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"empty", ""},
+		{"scheme", "ftp://discord.com/api/webhooks/123/token"},
+		{"host", "https://example.com/api/webhooks/123/token"},
+		{"lookalike host", "https://discord.com.example.com/api/webhooks/123/token"},
+		{"missing api", "https://discord.com/webhooks/123/token"},
+		{"version", "https://discord.com/api/vX/webhooks/123/token"},
+		{"missing token", "https://discord.com/api/webhooks/123"},
+		{"extra segment", "https://discord.com/api/webhooks/123/token/slack"},
+		{"numeric ID", "https://discord.com/api/webhooks/abc/token"},
+		{"token characters", "https://discord.com/api/webhooks/123/tok%20en"},
+		{"redacted token", "https://discord.com/api/webhooks/123/REDACTED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := discordwebhook.ParseWebhookURL(tt.raw)
+			if !errors.Is(err, discordwebhook.ErrInvalidWebhookURL) {
+				t.Fatalf("ParseWebhookURL(%q) error = %v, want ErrInvalidWebhookURL", tt.raw, err)
+			}
+			if tt.raw != "" && strings.Contains(err.Error(), tt.raw) {
+				t.Errorf("error %q leaks the URL", err)
+			}
+		})
+	}
+}
+
+func TestWebhookURLJSON(t *testing.T) {
+	var config struct {
+		Webhook discordwebhook.WebhookURL `json:"webhook"`
+	}
+	if err := json.Unmarshal([]byte(`{"webhook":"https://discord.com/api/webhooks/123/secret-token"}`), &config); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if strings.Contains(string(data), "secret-token") {
+		t.Fatalf("Marshal() = %s, leaks the token", data)
+	}
+
+	// Relire la configuration sérialisée donnerait un client sans jeton
+	err = json.Unmarshal(data, &config)
+	if !errors.Is(err, discordwebhook.ErrInvalidWebhookURL) {
+		t.Fatalf("Unmarshal(%s) error = %v, want ErrInvalidWebhookURL", data, err)
+	}
+}